@@ -0,0 +1,258 @@
+// Package testhelper provides a mock OCSP responder and probe constructor
+// for testing the OCSP probe without network access.
+package testhelper
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/probes/options"
+	"github.com/cloudprober/cloudprober/targets"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	ocspprobe "github.com/drivenet/cloudprober-ocsp/ocsp"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// NewMockOCSPServer starts an OCSP responder answering POST and GET requests
+// with the status and a nextUpdate the given duration from now. It returns
+// the server, a self-signed CA certificate and a leaf certificate issued by
+// the CA with the server as its OCSP responder. The server is closed when
+// the test finishes.
+func NewMockOCSPServer(t testing.TB, status int, nextUpdate time.Duration) (*httptest.Server, *x509.Certificate, *x509.Certificate) {
+	t.Helper()
+	return NewStaleMockOCSPServer(t, status, 0, nextUpdate)
+}
+
+// NewStaleMockOCSPServer is like NewMockOCSPServer, with thisUpdate of the
+// responses the given age in the past.
+func NewStaleMockOCSPServer(t testing.TB, status int, age, nextUpdate time.Duration) (*httptest.Server, *x509.Certificate, *x509.Certificate) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			der []byte
+			err error
+		)
+		switch r.Method {
+		case http.MethodPost:
+			der, err = io.ReadAll(r.Body)
+		case http.MethodGet:
+			var encoded string
+			if encoded, err = url.PathUnescape(path.Base(r.URL.EscapedPath())); err == nil {
+				der, err = base64.StdEncoding.DecodeString(encoded)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		req, err := ocsp.ParseRequest(der)
+		if err != nil {
+			_, _ = w.Write(ocsp.MalformedRequestErrorResponse)
+			return
+		}
+
+		now := time.Now()
+		template := ocsp.Response{
+			Status:       status,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   now.Add(-age),
+			NextUpdate:   now.Add(nextUpdate),
+		}
+		if status == ocsp.Revoked {
+			template.RevokedAt = now.Add(-time.Minute)
+			template.RevocationReason = ocsp.KeyCompromise
+		}
+		resp, err := ocsp.CreateResponse(ca, ca, template, caKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test.example.com"},
+		DNSNames:     []string{"test.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		OCSPServer:   []string{server.URL},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return server, ca, leaf
+}
+
+// NewCertChain returns a TLS certificate with a leaf certificate for
+// test.example.com, with the OCSP server if not empty, followed by its
+// self-signed issuer.
+func NewCertChain(t testing.TB, ocspServer string) *tls.Certificate {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test.example.com"},
+		DNSNames:     []string{"test.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ocspServer != "" {
+		leafTemplate.OCSPServer = []string{ocspServer}
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{leafDER, caDER},
+		PrivateKey:  leafKey,
+		Leaf:        leaf,
+	}
+}
+
+// WithMockTLSServer makes the probe download target certificates from an
+// in-memory TLS server presenting the certificates, instead of connecting to
+// the targets.
+func WithMockTLSServer(t testing.TB, certs []*tls.Certificate) func(*ocspprobe.Probe) {
+	t.Helper()
+
+	serverConfig := &tls.Config{}
+	for _, cert := range certs {
+		serverConfig.Certificates = append(serverConfig.Certificates, *cert)
+	}
+
+	return func(p *ocspprobe.Probe) {
+		p.DialTLSHook = func(_, _ string, config *tls.Config) (*tls.Conn, error) {
+			clientConn, serverConn := net.Pipe()
+			go func() {
+				server := tls.Server(serverConn, serverConfig)
+				defer func() { _ = server.Close() }()
+				if err := server.Handshake(); err != nil {
+					return
+				}
+				_, _ = io.Copy(io.Discard, server)
+			}()
+
+			conn := tls.Client(clientConn, config)
+			if err := conn.Handshake(); err != nil {
+				_ = conn.Close()
+				return nil, err
+			}
+			return conn, nil
+		}
+	}
+}
+
+// NewTestProbe returns an initialized OCSP probe for the targets. If
+// transport is not nil, it handles all OCSP requests of the probe. The
+// options, e.g. WithMockTLSServer, are applied before Init.
+func NewTestProbe(t testing.TB, conf *ocspprobe.ProbeConf, eps []endpoint.Endpoint, transport http.RoundTripper, probeOpts ...func(*ocspprobe.Probe)) *ocspprobe.Probe {
+	t.Helper()
+
+	opts := options.DefaultOptions()
+	opts.ProbeConf = conf
+	opts.Targets = targets.StaticEndpoints(eps)
+
+	p := &ocspprobe.Probe{}
+	if transport != nil {
+		p.TransportHook = transport.RoundTrip
+	}
+	for _, opt := range probeOpts {
+		opt(p)
+	}
+	if err := p.Init("test", opts); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
@@ -0,0 +1,29 @@
+package ocsp
+
+import (
+	"encoding/asn1"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// oidArchiveCutoff is the OCSP archive cutoff extension (RFC 6960, section
+// 4.4.4).
+var oidArchiveCutoff = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 6}
+
+// extractArchiveCutoff returns the archive cutoff of the OCSP response, and
+// false if the response has none or it can't be parsed.
+func extractArchiveCutoff(resp *ocsp.Response) (time.Time, bool) {
+	for _, ext := range resp.Extensions {
+		if !ext.Id.Equal(oidArchiveCutoff) {
+			continue
+		}
+
+		var cutoff time.Time
+		if rest, err := asn1.UnmarshalWithParams(ext.Value, &cutoff, "generalized"); err != nil || len(rest) > 0 {
+			return time.Time{}, false
+		}
+		return cutoff, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,173 @@
+package ocsp
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// ASN.1 structures of an OCSP request (RFC 6960, section 4.1.1). The
+// x/crypto/ocsp package only creates requests for a single certificate, so
+// batch requests are assembled from several single requests.
+type ocspRequestASN1 struct {
+	TBSRequest tbsRequestASN1
+}
+
+type tbsRequestASN1 struct {
+	Version           int              `asn1:"explicit,tag:0,default:0,optional"`
+	RequestorName     pkix.RDNSequence `asn1:"explicit,tag:1,optional"`
+	RequestList       []singleRequestASN1
+	RequestExtensions []pkix.Extension `asn1:"explicit,tag:2,optional"`
+}
+
+type singleRequestASN1 struct {
+	Cert certIDASN1
+}
+
+type certIDASN1 struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	NameHash      []byte
+	IssuerKeyHash []byte
+	SerialNumber  *big.Int
+}
+
+// createBatchRequest creates a single OCSP request for all certificates,
+// which must be issued by the same issuer.
+func createBatchRequest(certs []*x509.Certificate, issuer *x509.Certificate) ([]byte, error) {
+	var batch ocspRequestASN1
+	for _, cert := range certs {
+		der, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA1})
+		if err != nil {
+			return nil, err
+		}
+
+		var single ocspRequestASN1
+		if _, err := asn1.Unmarshal(der, &single); err != nil {
+			return nil, err
+		}
+		batch.TBSRequest.RequestList = append(batch.TBSRequest.RequestList, single.TBSRequest.RequestList...)
+	}
+	return asn1.Marshal(batch)
+}
+
+// ocspBatch holds the latest batch response of one OCSP server for all
+// certificates of one issuer.
+type ocspBatch struct {
+	sync.Mutex
+
+	fetched time.Time
+	call    *callResult
+	body    []byte
+	size    int
+	err     error
+}
+
+func issuerFingerprint(issuer *x509.Certificate) string {
+	sum := sha256.Sum256(issuer.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// batchFor returns the batch shared by all targets with the issuer using the
+// OCSP server, and the certificates of these targets.
+func (p *Probe) batchFor(issuer *x509.Certificate, serverUrl *url.URL) (*ocspBatch, []*x509.Certificate) {
+	fingerprint := issuerFingerprint(issuer)
+
+	var certs []*x509.Certificate
+	for _, key := range p.certKeys() {
+		cert, targetIssuer := p.getCert(key)
+		if cert == nil || targetIssuer == nil || issuerFingerprint(targetIssuer) != fingerprint {
+			continue
+		}
+		if !slices.ContainsFunc(cert.OCSPServer, func(server string) bool {
+			u, err := url.Parse(server)
+			return err == nil && u.String() == serverUrl.String()
+		}) {
+			continue
+		}
+		certs = append(certs, cert)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := fingerprint + "|" + serverUrl.Host
+	batch, ok := p.batches[key]
+	if !ok {
+		batch = &ocspBatch{}
+		p.batches[key] = batch
+	}
+	return batch, certs
+}
+
+// batchProbe returns the OCSP status of the target's certificate from the
+// batch response of the OCSP server. The batch request is only sent if no
+// other target sharing the issuer has done so within the last probe
+// interval; sent reports whether this call sent it.
+func (p *Probe) batchProbe(ctx context.Context, target endpoint.Endpoint, serverUrl *url.URL) (call *callResult, batchSize int, sent bool, err error) {
+	cert, issuer := p.getCert(target.Key())
+	if cert == nil || issuer == nil {
+		return nil, 0, false, fmt.Errorf("no certificates for target %s", target.Key())
+	}
+
+	batch, certs := p.batchFor(issuer, serverUrl)
+
+	batch.Lock()
+	defer batch.Unlock()
+
+	if time.Since(batch.fetched) >= p.opts.Interval {
+		sent = true
+		batch.fetched = time.Now()
+		batch.size = len(certs)
+		batch.call, batch.body, batch.err = p.sendBatch(ctx, certs, issuer, serverUrl)
+	}
+
+	call = &callResult{}
+	*call = *batch.call
+	if batch.err != nil {
+		return call, batch.size, sent, batch.err
+	}
+
+	if err := checkResponseSize(batch.body); err != nil {
+		return call, batch.size, sent, err
+	}
+
+	result, err := ocsp.ParseResponseForCert(batch.body, cert, issuer)
+	if err != nil {
+		return call, batch.size, sent, err
+	}
+
+	call.setResponse(result)
+
+	return call, batch.size, sent, nil
+}
+
+func (p *Probe) sendBatch(ctx context.Context, certs []*x509.Certificate, issuer *x509.Certificate, serverUrl *url.URL) (*callResult, []byte, error) {
+	body, err := createBatchRequest(certs, issuer)
+	if err != nil {
+		return &callResult{OCSPStatusCode: ocsp.ServerFailed}, nil, err
+	}
+
+	req, err := p.newOCSPRequest(serverUrl, body)
+	if err != nil {
+		return &callResult{OCSPStatusCode: ocsp.ServerFailed}, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+	defer cancel()
+
+	return fetchOCSPResponse(p.client, req.WithContext(ctx))
+}
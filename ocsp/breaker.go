@@ -0,0 +1,106 @@
+package ocsp
+
+import (
+	"time"
+)
+
+// breakerState is the certificate download circuit breaker of a target.
+type breakerState struct {
+	// Consecutive download failures and the end of the open circuit.
+	failures  int32
+	openUntil time.Time
+}
+
+// certDownloadAllowed returns false if the target's circuit breaker is open
+// and its last downloaded certificate is still valid, so that it can be
+// used instead.
+func (p *Probe) certDownloadAllowed(targetKey string) bool {
+	cert, _ := p.getCert(targetKey)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, ok := p.certDownloadBreaker[targetKey]
+	if !ok || !time.Now().Before(state.openUntil) {
+		return true
+	}
+	return cert == nil || time.Now().After(cert.NotAfter)
+}
+
+// recordCertDownload records the result of the target's certificate
+// download, opening the circuit breaker for cert_download_breaker_cooldown_sec
+// after cert_download_breaker_threshold consecutive failures.
+func (p *Probe) recordCertDownload(targetKey string, success bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if success {
+		delete(p.certDownloadBreaker, targetKey)
+		return
+	}
+
+	state, ok := p.certDownloadBreaker[targetKey]
+	if !ok {
+		state = &breakerState{}
+		p.certDownloadBreaker[targetKey] = state
+	}
+
+	state.failures++
+	if state.failures < p.c.GetCertDownloadBreakerThreshold() {
+		return
+	}
+
+	cooldown := time.Duration(p.c.GetCertDownloadBreakerCooldownSec()) * time.Second
+	state.openUntil = time.Now().Add(cooldown)
+	state.failures = 0
+	p.l.Warningf("Certificate download for target %s failed %d times in a row, not retrying for %s", targetKey, p.c.GetCertDownloadBreakerThreshold(), cooldown)
+}
+
+// certDownloadBreakerOpen returns true if the target's circuit breaker is
+// open. Must be called with the probe lock held.
+func (p *Probe) certDownloadBreakerOpen(targetKey string) bool {
+	state, ok := p.certDownloadBreaker[targetKey]
+	return ok && time.Now().Before(state.openUntil)
+}
+
+// backoffState is the certificate download backoff of a target.
+type backoffState struct {
+	nextAttempt time.Time
+	delay       time.Duration
+}
+
+// certDownloadBackedOff returns true if the target's certificate download
+// should be skipped until its backoff passes.
+func (p *Probe) certDownloadBackedOff(targetKey string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, ok := p.certDownloadBackoff[targetKey]
+	return ok && state.nextAttempt.After(time.Now())
+}
+
+// updateCertDownloadBackoff resets the target's backoff after a successful
+// download, or doubles it after a failure, starting at the targets update
+// interval and capped at max_cert_download_backoff_sec.
+func (p *Probe) updateCertDownloadBackoff(targetKey string, success bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if success {
+		delete(p.certDownloadBackoff, targetKey)
+		return
+	}
+
+	state, ok := p.certDownloadBackoff[targetKey]
+	if !ok {
+		state = &backoffState{}
+		p.certDownloadBackoff[targetKey] = state
+		p.l.Warningf("Certificate download for target %s failed, backing off", targetKey)
+	}
+
+	state.delay = max(2*state.delay, p.targetsUpdateInterval)
+	if maxDelay := time.Duration(p.c.GetMaxCertDownloadBackoffSec()) * time.Second; state.delay > maxDelay {
+		state.delay = maxDelay
+	}
+	state.nextAttempt = time.Now().Add(state.delay)
+}
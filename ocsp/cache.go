@@ -0,0 +1,96 @@
+package ocsp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+const responseCacheExt = ".ocsp"
+
+// responseCacheKey returns the cache key of the OCSP server's response for
+// the target. It's also the cache file name, without the extension.
+func responseCacheKey(targetKey, server string) string {
+	sum := sha256.Sum256([]byte(targetKey + server))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadResponseCache loads cached DER encoded OCSP responses from dir,
+// skipping responses which are past their nextUpdate.
+func (p *Probe) loadResponseCache(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+responseCacheExt))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		der, err := os.ReadFile(file)
+		if err != nil {
+			p.l.Warningf("Error reading cached OCSP response %s: %v", file, err)
+			continue
+		}
+
+		// The issuer is not known yet, the signature was verified when the
+		// response was cached.
+		resp, err := ocsp.ParseResponse(der, nil)
+		if err != nil {
+			p.l.Warningf("Error parsing cached OCSP response %s: %v", file, err)
+			continue
+		}
+
+		if !resp.NextUpdate.IsZero() && resp.NextUpdate.Before(time.Now()) {
+			continue
+		}
+
+		p.responseCache[strings.TrimSuffix(filepath.Base(file), responseCacheExt)] = resp
+	}
+
+	p.l.Infof("Loaded %d cached OCSP responses from %s", len(p.responseCache), dir)
+	return nil
+}
+
+// cachedResponse returns the cached OCSP server's response for the target,
+// or nil if there is none.
+func (p *Probe) cachedResponse(targetKey, server string) *ocsp.Response {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.responseCache[responseCacheKey(targetKey, server)]
+}
+
+// cacheResponse stores the OCSP server's response for the target in memory
+// and, if cache_dir is set, on disk. The file is replaced atomically.
+func (p *Probe) cacheResponse(targetKey, server string, resp *ocsp.Response) {
+	if p.c.GetCacheDir() == "" {
+		return
+	}
+
+	key := responseCacheKey(targetKey, server)
+
+	p.mu.Lock()
+	p.responseCache[key] = resp
+	p.mu.Unlock()
+
+	tmp, err := os.CreateTemp(p.c.GetCacheDir(), key+".*.tmp")
+	if err != nil {
+		p.l.Warningf("Error caching OCSP response: %v", err)
+		return
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(resp.Raw)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(p.c.GetCacheDir(), key+responseCacheExt))
+	}
+	if err != nil {
+		p.l.Warningf("Error caching OCSP response: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package ocsp
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudprober/cloudprober/config/runconfig"
+)
+
+// certExpiryPath is the path of the certificate expiry endpoint on
+// Cloudprober's HTTP server.
+const certExpiryPath = "/ocsp/cert-expiry"
+
+// certExpiryEntry is an entry of the certificate expiry HTTP endpoint.
+type certExpiryEntry struct {
+	Probe          string  `json:"probe"`
+	Target         string  `json:"target"`
+	ExpiryUnix     int64   `json:"expiry_unix"`
+	DaysRemaining  float64 `json:"days_remaining"`
+	SerialHex      string  `json:"serial_hex"`
+	CN             string  `json:"cn"`
+	LastOCSPStatus string  `json:"last_ocsp_status"`
+}
+
+// certExpiryProbes are the OCSP probes served by the certificate expiry
+// endpoint, keyed by probe name, and the muxes the endpoint is registered
+// with. The endpoint is shared by all probes, as a ServeMux panics if a
+// path is registered twice.
+var certExpiryProbes = struct {
+	sync.Mutex
+	probes map[string]*Probe
+	muxes  map[*http.ServeMux]bool
+}{
+	probes: make(map[string]*Probe),
+	muxes:  make(map[*http.ServeMux]bool),
+}
+
+// registerCertExpiryHandler adds the probe to the certificate expiry
+// endpoint on Cloudprober's HTTP server, if it's running. A probe
+// initialized again replaces the earlier instance with the same name.
+func (p *Probe) registerCertExpiryHandler() {
+	mux := runconfig.DefaultHTTPServeMux()
+	if mux == nil {
+		return
+	}
+
+	certExpiryProbes.Lock()
+	defer certExpiryProbes.Unlock()
+
+	certExpiryProbes.probes[p.name] = p
+	if !certExpiryProbes.muxes[mux] {
+		mux.HandleFunc(certExpiryPath, certExpiryHandler)
+		certExpiryProbes.muxes[mux] = true
+		p.l.Infof("Serving certificate expiry at %s", certExpiryPath)
+	}
+}
+
+// certExpiryHandler serves the certificates of the targets of all OCSP
+// probes as a JSON array sorted by expiry, soonest first.
+func certExpiryHandler(w http.ResponseWriter, r *http.Request) {
+	certExpiryProbes.Lock()
+	probes := make([]*Probe, 0, len(certExpiryProbes.probes))
+	for _, p := range certExpiryProbes.probes {
+		probes = append(probes, p)
+	}
+	certExpiryProbes.Unlock()
+
+	entries := []certExpiryEntry{}
+	for _, p := range probes {
+		entries = append(entries, p.certExpiryEntries()...)
+	}
+	writeCertExpiry(w, entries)
+}
+
+// CertExpiryHandler serves the certificates of the probe's targets as a
+// JSON array sorted by expiry, soonest first.
+func (p *Probe) CertExpiryHandler(w http.ResponseWriter, r *http.Request) {
+	writeCertExpiry(w, p.certExpiryEntries())
+}
+
+// certExpiryEntries returns the certificate expiry entries of the probe's
+// targets with a certificate. The last OCSP status is the worst status
+// reported by the target's OCSP servers, empty if none responded yet.
+func (p *Probe) certExpiryEntries() []certExpiryEntry {
+	var entries []certExpiryEntry
+	for _, target := range p.opts.Targets.ListEndpoints() {
+		cert, _ := p.getCert(target.Key())
+		if cert == nil {
+			continue
+		}
+
+		entry := certExpiryEntry{
+			Probe:         p.name,
+			Target:        target.Name,
+			ExpiryUnix:    cert.NotAfter.Unix(),
+			DaysRemaining: time.Until(cert.NotAfter).Hours() / 24,
+			SerialHex:     cert.SerialNumber.Text(16),
+			CN:            cert.Subject.CommonName,
+		}
+
+		status := -1
+		p.mu.Lock()
+		for key, s := range p.serverStatusHistory {
+			if strings.HasPrefix(key, target.Key()+"|") {
+				status = worseOCSPStatus(status, s)
+			}
+		}
+		p.mu.Unlock()
+		if status >= 0 {
+			entry.LastOCSPStatus = ocspStatusString(status)
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// writeCertExpiry writes the entries as JSON, sorted by expiry.
+func writeCertExpiry(w http.ResponseWriter, entries []certExpiryEntry) {
+	if entries == nil {
+		entries = []certExpiryEntry{}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ExpiryUnix < entries[j].ExpiryUnix
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,322 @@
+package ocsp
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// oidEmbeddedSCTList is the X.509v3 extension carrying the embedded signed
+// certificate timestamp list (RFC 6962, section 3.3).
+var oidEmbeddedSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// oidTLSFeature is the TLS feature extension (RFC 7633), and
+// tlsFeatureStatusRequest its status_request feature, requiring OCSP
+// stapling ("must-staple").
+var oidTLSFeature = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}
+
+const tlsFeatureStatusRequest = 5
+
+// validationTypePolicies maps CA/Browser Forum certificate policy OIDs to
+// the validation type they assert.
+var validationTypePolicies = map[string]string{
+	"2.23.140.1.1":   "EV",
+	"2.23.140.1.2.1": "DV",
+	"2.23.140.1.2.2": "OV",
+}
+
+// builtinEVOIDs maps EV policy OIDs of well-known CAs to the CA name. The
+// CA/Browser Forum EV OID is asserted in addition to these by current EV
+// certificates.
+var builtinEVOIDs = map[string]string{
+	"2.23.140.1.1":                 "CA/Browser Forum",
+	"2.16.840.1.114412.2.1":        "DigiCert",
+	"1.3.6.1.4.1.4146.1.1":         "GlobalSign",
+	"2.16.840.1.114413.1.7.23.3":   "GoDaddy",
+	"2.16.840.1.114414.1.7.23.3":   "Starfield",
+	"1.3.6.1.4.1.6449.1.2.1.5.1":   "Sectigo",
+	"2.16.840.1.113733.1.7.23.6":   "VeriSign",
+	"1.3.6.1.4.1.14370.1.6":        "GeoTrust",
+	"2.16.840.1.114028.10.1.2":     "Entrust",
+	"1.3.6.1.4.1.8024.0.2.100.1.2": "QuoVadis",
+	"2.16.756.1.89.1.2.1.1":        "SwissSign",
+}
+
+// certMeta holds per-target details about the server certificate, collected
+// when the certificate is downloaded and exported with probe results.
+type certMeta struct {
+	// Number of SCTs delivered in the TLS extension and embedded in the
+	// certificate itself.
+	sctTLSExtension int64
+	sctEmbedded     int64
+
+	// Whether the certificate may be used for TLS server authentication.
+	serverAuthEKU bool
+
+	// Validation type of the certificate: "EV", "OV", "DV" or "unknown".
+	validationType string
+
+	// Name of the CA asserting EV, see certEVCAName.
+	evCAName string
+
+	// Organization of the certificate subject, see certOrgLabel.
+	org string
+
+	// Public key algorithm and size or curve, see certKeyDetail.
+	keyDetail string
+
+	// Base64 encoded SHA-256 hash of the certificate's SubjectPublicKeyInfo,
+	// as used for key pinning (RFC 7469).
+	spkiHash string
+
+	// Number of certificates served by the target.
+	chainLength int64
+
+	// Whether the certificate requires OCSP stapling, and whether the
+	// target stapled an OCSP response when it was downloaded.
+	mustStaple bool
+	stapled    bool
+
+	// Start of the certificate validity and its total span, in days.
+	notBefore        time.Time
+	validitySpanDays float64
+
+	// TLS version negotiated when downloading the certificate.
+	tlsVersion string
+
+	// Whether the certificate is valid for the target's hostname.
+	hostnameMatch bool
+
+	// Number of subject alternative names, and of wildcard DNS names among
+	// them.
+	sanCount      int64
+	wildcardCount int64
+}
+
+func newCertMeta(cert *x509.Certificate, state *tls.ConnectionState, evPolicyOIDs []string) *certMeta {
+	return &certMeta{
+		sctTLSExtension:  int64(len(state.SignedCertificateTimestamps)),
+		sctEmbedded:      embeddedSCTCount(cert),
+		serverAuthEKU:    hasServerAuthEKU(cert),
+		validationType:   classifyCertValidationType(cert, evPolicyOIDs),
+		evCAName:         certEVCAName(cert, evPolicyOIDs),
+		org:              certOrgLabel(cert),
+		keyDetail:        certKeyDetail(cert),
+		spkiHash:         spkiHash(cert),
+		mustStaple:       hasMustStaple(cert),
+		stapled:          len(state.OCSPResponse) > 0,
+		chainLength:      int64(len(state.PeerCertificates)),
+		notBefore:        cert.NotBefore,
+		validitySpanDays: cert.NotAfter.Sub(cert.NotBefore).Hours() / 24,
+		tlsVersion:       tlsVersionName(state.Version),
+		sanCount:         int64(len(cert.DNSNames) + len(cert.IPAddresses) + len(cert.EmailAddresses)),
+		wildcardCount:    wildcardSANCount(cert),
+	}
+}
+
+// maxCertOrgLen is the maximum length of the cert_org label, in characters.
+const maxCertOrgLen = 64
+
+// certOrgLabel returns the first organization of the certificate subject,
+// truncated to maxCertOrgLen characters, or an empty string if it has none.
+func certOrgLabel(cert *x509.Certificate) string {
+	if cert == nil || len(cert.Subject.Organization) == 0 {
+		return ""
+	}
+
+	org := []rune(cert.Subject.Organization[0])
+	if len(org) > maxCertOrgLen {
+		org = org[:maxCertOrgLen]
+	}
+	return string(org)
+}
+
+// certKeyDetail describes the certificate's public key, e.g. "RSA-2048",
+// "ECDSA-P256" or "Ed25519".
+func certKeyDetail(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA-%d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA-" + strings.ReplaceAll(key.Curve.Params().Name, "-", "")
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+	return cert.PublicKeyAlgorithm.String()
+}
+
+// hasMustStaple returns true if the certificate's TLS feature extension
+// requires the status_request feature.
+func hasMustStaple(cert *x509.Certificate) bool {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidTLSFeature) {
+			continue
+		}
+
+		var features []int
+		if _, err := asn1.Unmarshal(ext.Value, &features); err != nil {
+			return false
+		}
+		return slices.Contains(features, tlsFeatureStatusRequest)
+	}
+	return false
+}
+
+// spkiHash returns the base64 encoded SHA-256 hash of the certificate's
+// SubjectPublicKeyInfo.
+func spkiHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// wildcardSANCount returns the number of wildcard DNS names of the
+// certificate.
+func wildcardSANCount(cert *x509.Certificate) int64 {
+	var n int64
+	for _, name := range cert.DNSNames {
+		if strings.HasPrefix(name, "*.") {
+			n++
+		}
+	}
+	return n
+}
+
+// classifyCertValidationType returns the validation type asserted by the
+// certificate's policies, checking customEV OIDs in addition to the
+// CA/Browser Forum ones. EV takes precedence over OV, and OV over DV.
+func classifyCertValidationType(cert *x509.Certificate, customEV []string) string {
+	found := make(map[string]bool)
+	for _, oid := range cert.PolicyIdentifiers {
+		if _, ok := builtinEVOIDs[oid.String()]; ok || slices.Contains(customEV, oid.String()) {
+			found["EV"] = true
+		}
+		if validationType, ok := validationTypePolicies[oid.String()]; ok {
+			found[validationType] = true
+		}
+	}
+
+	for _, validationType := range []string{"EV", "OV", "DV"} {
+		if found[validationType] {
+			return validationType
+		}
+	}
+	return "unknown"
+}
+
+// certEVCAName returns the name of the CA whose EV policy the certificate
+// asserts, "custom" for one of customEV, or an empty string if it's not an
+// EV certificate. CA specific OIDs take precedence over the CA/Browser Forum
+// one.
+func certEVCAName(cert *x509.Certificate, customEV []string) string {
+	var name string
+	for _, oid := range cert.PolicyIdentifiers {
+		switch caName, ok := builtinEVOIDs[oid.String()]; {
+		case ok && oid.String() != "2.23.140.1.1":
+			return caName
+		case slices.Contains(customEV, oid.String()):
+			name = "custom"
+		case ok && name == "":
+			name = caName
+		}
+	}
+	return name
+}
+
+// tlsVersions maps names of TLS versions to their values.
+var tlsVersions = map[string]uint16{
+	"TLS10": tls.VersionTLS10,
+	"TLS11": tls.VersionTLS11,
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
+// tlsVersionName returns the name of the TLS version, e.g. "TLS12".
+func tlsVersionName(version uint16) string {
+	for name, v := range tlsVersions {
+		if v == version {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%04x", version)
+}
+
+// hasServerAuthEKU returns true if the certificate's extended key usage
+// allows TLS server authentication.
+func hasServerAuthEKU(cert *x509.Certificate) bool {
+	for _, usage := range cert.ExtKeyUsage {
+		if usage == x509.ExtKeyUsageServerAuth || usage == x509.ExtKeyUsageAny {
+			return true
+		}
+	}
+	return false
+}
+
+// sctCount returns the total number of SCTs from all sources.
+func (m *certMeta) sctCount() int64 {
+	return m.sctTLSExtension + m.sctEmbedded
+}
+
+// embeddedSCTCount returns the number of SCTs in the certificate's embedded
+// SCT list. The extension value is an OCTET STRING wrapping a TLS-encoded
+// SignedCertificateTimestampList: a 2-byte total length followed by
+// 2-byte length prefixed SCTs.
+func embeddedSCTCount(cert *x509.Certificate) int64 {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidEmbeddedSCTList) {
+			continue
+		}
+
+		var list []byte
+		if _, err := asn1.Unmarshal(ext.Value, &list); err != nil || len(list) < 2 {
+			return 0
+		}
+
+		if int(list[0])<<8|int(list[1]) != len(list)-2 {
+			return 0
+		}
+		list = list[2:]
+
+		var n int64
+		for len(list) >= 2 {
+			size := int(list[0])<<8 | int(list[1])
+			list = list[2:]
+			if size > len(list) {
+				break
+			}
+			list = list[size:]
+			n++
+		}
+		return n
+	}
+	return 0
+}
+
+// diffCerts describes how the new certificate differs from the old one as a
+// comma separated list of "serial", "not_after" and "subject", or "other" if
+// none of these changed.
+func diffCerts(old, new *x509.Certificate) string {
+	var changes []string
+	if old.SerialNumber.Cmp(new.SerialNumber) != 0 {
+		changes = append(changes, "serial")
+	}
+	if !old.NotAfter.Equal(new.NotAfter) {
+		changes = append(changes, "not_after")
+	}
+	if !bytes.Equal(old.RawSubject, new.RawSubject) {
+		changes = append(changes, "subject")
+	}
+	if len(changes) == 0 {
+		return "other"
+	}
+	return strings.Join(changes, ",")
+}
@@ -0,0 +1,100 @@
+package ocsp
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCertValidationType(t *testing.T) {
+	tests := []struct {
+		name         string
+		policies     []string
+		customEV     []string
+		wantType     string
+		wantEVCAName string
+	}{
+		{
+			name:     "no policies",
+			wantType: "unknown",
+		},
+		{
+			name:         "CA/Browser Forum EV",
+			policies:     []string{"2.23.140.1.1"},
+			wantType:     "EV",
+			wantEVCAName: "CA/Browser Forum",
+		},
+		{
+			name:     "CA/Browser Forum DV",
+			policies: []string{"2.23.140.1.2.1"},
+			wantType: "DV",
+		},
+		{
+			name:     "CA/Browser Forum OV",
+			policies: []string{"2.23.140.1.2.2"},
+			wantType: "OV",
+		},
+		{
+			name:     "OV takes precedence over DV",
+			policies: []string{"2.23.140.1.2.1", "2.23.140.1.2.2"},
+			wantType: "OV",
+		},
+		{
+			name:         "CA specific EV",
+			policies:     []string{"2.23.140.1.1", "2.16.840.1.114412.2.1"},
+			wantType:     "EV",
+			wantEVCAName: "DigiCert",
+		},
+		{
+			name:     "unknown policy",
+			policies: []string{"1.2.3.4"},
+			wantType: "unknown",
+		},
+		{
+			name:         "configured EV",
+			policies:     []string{"1.2.3.4"},
+			customEV:     []string{"1.2.3.4"},
+			wantType:     "EV",
+			wantEVCAName: "custom",
+		},
+		{
+			name:         "configured EV takes precedence over CA/Browser Forum",
+			policies:     []string{"2.23.140.1.1", "1.2.3.4"},
+			customEV:     []string{"1.2.3.4"},
+			wantType:     "EV",
+			wantEVCAName: "custom",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cert := &x509.Certificate{}
+			for _, policy := range test.policies {
+				cert.PolicyIdentifiers = append(cert.PolicyIdentifiers, parseOID(t, policy))
+			}
+
+			if got := classifyCertValidationType(cert, test.customEV); got != test.wantType {
+				t.Errorf("classifyCertValidationType() = %s, want %s", got, test.wantType)
+			}
+			if got := certEVCAName(cert, test.customEV); got != test.wantEVCAName {
+				t.Errorf("certEVCAName() = %q, want %q", got, test.wantEVCAName)
+			}
+		})
+	}
+}
+
+func parseOID(t *testing.T, s string) asn1.ObjectIdentifier {
+	t.Helper()
+
+	var oid asn1.ObjectIdentifier
+	for _, arc := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(arc)
+		if err != nil {
+			t.Fatalf("invalid OID %s: %v", s, err)
+		}
+		oid = append(oid, n)
+	}
+	return oid
+}
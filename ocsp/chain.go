@@ -0,0 +1,238 @@
+package ocsp
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// chainRollup is the OCSP status rollup of the target's chain: "all_good",
+// "revoked", "unknown" or "error", and the depth of the first certificate
+// which isn't good, -1 if all are.
+type chainRollup struct {
+	status       string
+	firstNonGood int
+}
+
+// chainKey identifies probe results of one OCSP server of a certificate in
+// the target's chain.
+type chainKey struct {
+	depth  int
+	server string
+}
+
+// runChainProbe checks the OCSP status of the intermediate certificates in
+// the target's chain, using the next certificate in the chain as the issuer,
+// and updates the chain status rollup. The issuer of the last certificate is
+// fetched from its AIA URL if the target doesn't serve it. Leaf (depth 0)
+// results are taken from leafResults.
+func (p *Probe) runChainProbe(ctx context.Context, target endpoint.Endpoint, leafResults map[string]*probeResult, results map[chainKey]*probeResult) {
+	p.mu.Lock()
+	chain := p.chains[target.Key()]
+	p.mu.Unlock()
+
+	// Status of the certificate at each depth, -1 if not known.
+	if len(chain) == 0 {
+		return
+	}
+
+	statuses := make([]int, len(chain))
+	for i := range statuses {
+		statuses[i] = -1
+	}
+
+	// Whether an OCSP request for the certificate at each depth failed.
+	failed := make([]bool, len(chain))
+	for _, result := range leafResults {
+		statuses[0] = worseOCSPStatus(statuses[0], result.runStatus)
+		failed[0] = failed[0] || result.runFailed
+	}
+
+	for depth := 1; depth < len(chain); depth++ {
+		cert := chain[depth]
+		if bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+			// Self-signed root, nothing to check.
+			break
+		}
+		if len(cert.OCSPServer) == 0 {
+			continue
+		}
+
+		var issuer *x509.Certificate
+		if depth+1 < len(chain) {
+			issuer = chain[depth+1]
+		} else if issuer = p.chainIssuer(cert); issuer == nil {
+			p.l.Warningf("Target: %s, cannot fetch issuer of certificate at depth %d", target.Name, depth)
+			continue
+		}
+
+		body, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA1})
+		if err != nil {
+			p.l.Warningf("Target: %s, cannot create OCSP request for certificate at depth %d: %v", target.Name, depth, err)
+			failed[depth] = true
+			continue
+		}
+
+		for _, server := range cert.OCSPServer {
+			serverUrl, err := url.Parse(server)
+			if err != nil {
+				continue
+			}
+
+			key := chainKey{depth: depth, server: serverUrl.Host}
+			result, ok := results[key]
+			if !ok {
+				result = p.newResult()
+				results[key] = result
+			}
+
+			req, err := p.newOCSPRequest(serverUrl, body)
+			if err != nil {
+				continue
+			}
+
+			reqCtx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+			res, err := ocspProbe(p.client, req.WithContext(reqCtx), issuer)
+			cancel()
+
+			result.total++
+			if err != nil {
+				if isClientTimeout(err) {
+					result.timeouts++
+				}
+				p.l.Warningf("Target: %s, URL: %s, OCSP request for certificate at depth %d failed: %v", target.Name, server, depth, err)
+				failed[depth] = true
+				continue
+			}
+
+			if res.OCSPStatusCode == ocsp.Revoked {
+				p.l.Warningf("Target: %s, URL: %s, certificate at depth %d (%s) is revoked", target.Name, server, depth, cert.Subject)
+			}
+
+			result.success++
+			result.lastStatus = res.OCSPStatusCode
+			result.respCodes.IncKey(strconv.FormatInt(int64(res.HTTPStatusCode), 10))
+			result.countOCSPStatus(res.OCSPStatusCode)
+			result.latency.AddFloat64(res.spent.Seconds() / p.opts.LatencyUnit.Seconds())
+
+			statuses[depth] = worseOCSPStatus(statuses[depth], res.OCSPStatusCode)
+		}
+	}
+
+	rollup := chainOCSPStatus(statuses, failed)
+
+	p.mu.Lock()
+	p.chainStatus[target.Key()] = rollup
+	p.mu.Unlock()
+}
+
+// validateChainLinkage checks that the authority key identifier of each
+// certificate in the chain matches the subject key identifier of the next
+// one. Certificates lacking either identifier are not checked.
+func validateChainLinkage(chain []*x509.Certificate) error {
+	for depth := 0; depth+1 < len(chain); depth++ {
+		akid, skid := chain[depth].AuthorityKeyId, chain[depth+1].SubjectKeyId
+		if len(akid) == 0 || len(skid) == 0 {
+			continue
+		}
+		if !bytes.Equal(akid, skid) {
+			return fmt.Errorf("authority key ID of certificate at depth %d (%s) doesn't match subject key ID of certificate at depth %d (%s)", depth, chain[depth].Subject, depth+1, chain[depth+1].Subject)
+		}
+	}
+	return nil
+}
+
+// issuerFromChain returns the issuer of the leaf certificate from the chain
+// presented by the target, or nil if the chain doesn't include it. The
+// issuer's subject must match the leaf's issuer, and its subject key
+// identifier the leaf's authority key identifier.
+func issuerFromChain(leaf *x509.Certificate, chain []*x509.Certificate) *x509.Certificate {
+	for _, cert := range chain {
+		if cert == leaf || !bytes.Equal(cert.RawSubject, leaf.RawIssuer) {
+			continue
+		}
+		if len(leaf.AuthorityKeyId) > 0 && !bytes.Equal(cert.SubjectKeyId, leaf.AuthorityKeyId) {
+			continue
+		}
+		return cert
+	}
+	return nil
+}
+
+// chainIssuer returns the issuer of the chain certificate, fetched from its
+// AIA URLs and cached, or nil if it can't be fetched.
+func (p *Probe) chainIssuer(cert *x509.Certificate) *x509.Certificate {
+	for _, issuingCert := range cert.IssuingCertificateURL {
+		p.mu.Lock()
+		issuer, ok := p.chainIssuers[issuingCert]
+		p.mu.Unlock()
+		if ok {
+			return issuer
+		}
+
+		issuer, err := p.fetchRemote(issuingCert)
+		if err != nil {
+			continue
+		}
+
+		p.mu.Lock()
+		p.chainIssuers[issuingCert] = issuer
+		p.mu.Unlock()
+		return issuer
+	}
+	return nil
+}
+
+// chainOCSPStatus rolls up OCSP statuses of the certificates in the chain,
+// indexed by depth, and whether requests for them failed. Any revoked
+// certificate makes the chain "revoked", otherwise any unknown one
+// "unknown", otherwise any failed request "error".
+func chainOCSPStatus(statuses []int, failed []bool) chainRollup {
+	rollup := chainRollup{status: "all_good", firstNonGood: -1}
+	for depth, status := range statuses {
+		var depthStatus string
+		switch {
+		case status == ocsp.Revoked:
+			depthStatus = "revoked"
+		case status == ocsp.Unknown || status == ocsp.ServerFailed:
+			depthStatus = "unknown"
+		case failed[depth]:
+			depthStatus = "error"
+		default:
+			continue
+		}
+
+		if rollup.firstNonGood < 0 {
+			rollup.firstNonGood = depth
+		}
+		if chainStatusSeverity[depthStatus] > chainStatusSeverity[rollup.status] {
+			rollup.status = depthStatus
+		}
+	}
+	return rollup
+}
+
+// chainStatusSeverity orders chain rollup statuses by severity.
+var chainStatusSeverity = map[string]int{
+	"all_good": 0,
+	"error":    1,
+	"unknown":  2,
+	"revoked":  3,
+}
+
+// worseOCSPStatus returns the more severe of two OCSP statuses, where -1
+// means no status. Revoked is the most severe status.
+func worseOCSPStatus(a, b int) int {
+	if a == ocsp.Revoked || b == ocsp.Revoked {
+		return ocsp.Revoked
+	}
+	return max(a, b)
+}
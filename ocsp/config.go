@@ -0,0 +1,102 @@
+package ocsp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// validateConfig checks the probe config, returning an error naming the
+// first invalid field.
+func (p *Probe) validateConfig(c *ProbeConf) error {
+	fieldErr := func(field string, err error) error {
+		return fmt.Errorf("field %s: %v", field, err)
+	}
+	oneOf := func(field, value string, allowed ...string) error {
+		if slices.Contains(allowed, value) {
+			return nil
+		}
+		return fieldErr(field, fmt.Errorf("invalid value %q, must be one of %q", value, allowed))
+	}
+
+	if proxyURL := c.GetProxyUrl(); proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fieldErr("proxy_url", err)
+		}
+		if err := oneOf("proxy_url", u.Scheme, "http", "https", "socks5"); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range []struct{ field, name string }{
+		{"ocsp_client_cert_file", c.GetOcspClientCertFile()},
+		{"ocsp_client_key_file", c.GetOcspClientKeyFile()},
+	} {
+		if file.name == "" {
+			continue
+		}
+		f, err := os.Open(file.name)
+		if err != nil {
+			return fieldErr(file.field, err)
+		}
+		_ = f.Close()
+	}
+
+	if c.GetCertExpiryWarningDays() <= c.GetCertExpiryCriticalDays() {
+		return fieldErr("cert_expiry_warning_days", errors.New("must be greater than cert_expiry_critical_days"))
+	}
+
+	if c.GetMaxAiaRedirects() < 0 {
+		return fieldErr("max_aia_redirects", errors.New("must not be negative"))
+	}
+
+	if c.GetCertUpdateWorkers() < 1 {
+		return fieldErr("cert_update_workers", errors.New("must be positive"))
+	}
+
+	if alpha := c.GetEwmaAlpha(); alpha <= 0 || alpha > 1 {
+		return fieldErr("ewma_alpha", errors.New("must be in (0, 1]"))
+	}
+
+	if c.GetUseHttpCaching() {
+		if p.requestMethod() != http.MethodGet {
+			return fieldErr("use_http_caching", errors.New("requires request_method GET or rfc5019_mode"))
+		}
+		if p.useNonce() {
+			return fieldErr("use_http_caching", errors.New("can't be used with use_nonce"))
+		}
+	}
+
+	if c.GetVaultAddr() != "" && c.GetVaultPkiRole() == "" {
+		return fieldErr("vault_pki_role", errors.New("required with vault_addr"))
+	}
+
+	if c.GetCertSourceS3Bucket() != "" {
+		if c.GetCertSourceS3Key() == "" {
+			return fieldErr("cert_source_s3_key", errors.New("required with cert_source_s3_bucket"))
+		}
+		if c.GetVaultAddr() != "" {
+			return fieldErr("cert_source_s3_bucket", errors.New("can't be used with vault_addr"))
+		}
+	}
+
+	if err := oneOf("request_method", c.GetRequestMethod(), http.MethodPost, http.MethodGet); err != nil {
+		return err
+	}
+	if err := oneOf("log_format", c.GetLogFormat(), "text", "json"); err != nil {
+		return err
+	}
+	if err := oneOf("min_tls_version", c.GetMinTlsVersion(), "", "TLS12", "TLS13"); err != nil {
+		return err
+	}
+	if err := oneOf("ip_version", c.GetIpVersion(), "ipv4", "ipv6", "any"); err != nil {
+		return err
+	}
+
+	return nil
+}
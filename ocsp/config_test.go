@@ -0,0 +1,64 @@
+package ocsp
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func TestValidateConfigHTTPCaching(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    *ProbeConf
+		wantErr bool
+	}{
+		{
+			name: "GET",
+			conf: &ProbeConf{
+				UseHttpCaching: proto.Bool(true),
+				RequestMethod:  proto.String("GET"),
+			},
+		},
+		{
+			name: "POST",
+			conf: &ProbeConf{
+				UseHttpCaching: proto.Bool(true),
+			},
+			wantErr: true,
+		},
+		{
+			name: "rfc5019_mode",
+			conf: &ProbeConf{
+				UseHttpCaching: proto.Bool(true),
+				Rfc5019Mode:    proto.Bool(true),
+			},
+		},
+		{
+			name: "use_nonce",
+			conf: &ProbeConf{
+				UseHttpCaching: proto.Bool(true),
+				RequestMethod:  proto.String("GET"),
+				UseNonce:       proto.Bool(true),
+			},
+			wantErr: true,
+		},
+		{
+			name: "use_nonce in rfc5019_mode",
+			conf: &ProbeConf{
+				UseHttpCaching: proto.Bool(true),
+				Rfc5019Mode:    proto.Bool(true),
+				UseNonce:       proto.Bool(true),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &Probe{c: test.conf}
+			err := p.validateConfig(test.conf)
+			if (err != nil) != test.wantErr {
+				t.Errorf("validateConfig() = %v, want error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package ocsp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// newResolver returns a resolver sending all DNS queries to the DNS server
+// at addr.
+func newResolver(addr string) *net.Resolver {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(strings.Trim(addr, "[]"), "53")
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// dnsCacheEntry holds the resolved addresses of a host until expiry.
+type dnsCacheEntry struct {
+	addrs  []string
+	expiry time.Time
+}
+
+// dnsCachingDialer wraps net.Dialer and caches DNS lookups for ttl, so that
+// probing with short intervals doesn't resolve OCSP servers on every new
+// connection.
+type dnsCachingDialer struct {
+	dialer *net.Dialer
+	ttl    time.Duration
+
+	// Cached lookups, keyed by host.
+	cache sync.Map
+
+	hits, misses atomic.Int64
+}
+
+// DialContext resolves the host of addr, using the cache if possible, and
+// connects to the first reachable address matching the network.
+func (d *dnsCachingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if d.ttl <= 0 {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs = filterAddrs(network, addrs)
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no %s addresses for host %s", network, host)
+	}
+
+	for _, ip := range addrs {
+		var conn net.Conn
+		conn, err = d.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// lookup returns the addresses of the host from the cache, resolving it if
+// the cached entry is missing or expired.
+func (d *dnsCachingDialer) lookup(ctx context.Context, host string) ([]string, error) {
+	if v, ok := d.cache.Load(host); ok {
+		entry := v.(*dnsCacheEntry)
+		if time.Now().Before(entry.expiry) {
+			d.hits.Add(1)
+			return entry.addrs, nil
+		}
+	}
+	d.misses.Add(1)
+
+	ipAddrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &dnsCacheEntry{
+		expiry: time.Now().Add(d.ttl),
+	}
+	for _, ipAddr := range ipAddrs {
+		entry.addrs = append(entry.addrs, ipAddr.String())
+	}
+	d.cache.Store(host, entry)
+
+	return entry.addrs, nil
+}
+
+// filterAddrs returns the addresses usable with the network.
+func filterAddrs(network string, addrs []string) []string {
+	if network != "tcp4" && network != "tcp6" {
+		return addrs
+	}
+
+	var filtered []string
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			continue
+		}
+		if (ip.To4() != nil) == (network == "tcp4") {
+			filtered = append(filtered, addr)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,116 @@
+package ocsp_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/drivenet/cloudprober-ocsp/internal/testhelper"
+	ocspprobe "github.com/drivenet/cloudprober-ocsp/ocsp"
+)
+
+func TestDownloadServerCertificate(t *testing.T) {
+	chain := testhelper.NewCertChain(t, "http://ocsp.example.com")
+	p := testhelper.NewTestProbe(t, &ocspprobe.ProbeConf{}, nil, nil, testhelper.WithMockTLSServer(t, []*tls.Certificate{chain}))
+
+	cert, state, err := p.DownloadServerCertificate("test.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert.Equal(chain.Leaf) {
+		t.Errorf("got certificate %s, want %s", cert.Subject, chain.Leaf.Subject)
+	}
+	if len(state.PeerCertificates) != 2 {
+		t.Errorf("got %d certificates in the chain, want 2", len(state.PeerCertificates))
+	}
+}
+
+func TestUpdateCertificatesIssuerFromChain(t *testing.T) {
+	chain := testhelper.NewCertChain(t, "http://ocsp.example.com")
+	other := testhelper.NewCertChain(t, "http://ocsp.example.com")
+
+	tests := []struct {
+		name  string
+		chain [][]byte
+	}{
+		{
+			name:  "issuer after leaf",
+			chain: chain.Certificate,
+		},
+		{
+			// Both CAs have the same subject, the issuer is matched by its
+			// key ID.
+			name:  "other CA before issuer",
+			chain: [][]byte{chain.Certificate[0], other.Certificate[1], chain.Certificate[1]},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			served := *chain
+			served.Certificate = test.chain
+			target := endpoint.Endpoint{Name: "test.example.com"}
+			p := testhelper.NewTestProbe(t, &ocspprobe.ProbeConf{}, []endpoint.Endpoint{target}, nil, testhelper.WithMockTLSServer(t, []*tls.Certificate{&served}))
+
+			p.UpdateCertificates()
+
+			cert, issuer := p.Cert(target)
+			if cert == nil || !cert.Equal(chain.Leaf) {
+				t.Fatal("leaf certificate not stored")
+			}
+			if issuer == nil || !issuer.Equal(mustParseCertificate(t, chain.Certificate[1])) {
+				t.Errorf("got issuer %v, want the leaf's CA", issuer)
+			}
+		})
+	}
+}
+
+func TestUpdateCertificatesChanged(t *testing.T) {
+	first := testhelper.NewCertChain(t, "http://ocsp.example.com")
+	second := testhelper.NewCertChain(t, "http://ocsp.example.com")
+	target := endpoint.Endpoint{Name: "test.example.com"}
+
+	var (
+		serveSecond atomic.Bool
+		mocks       [2]*ocspprobe.Probe
+	)
+	mocks[0], mocks[1] = &ocspprobe.Probe{}, &ocspprobe.Probe{}
+	testhelper.WithMockTLSServer(t, []*tls.Certificate{first})(mocks[0])
+	testhelper.WithMockTLSServer(t, []*tls.Certificate{second})(mocks[1])
+
+	p := testhelper.NewTestProbe(t, &ocspprobe.ProbeConf{}, []endpoint.Endpoint{target}, nil, func(p *ocspprobe.Probe) {
+		p.DialTLSHook = func(network, addr string, config *tls.Config) (*tls.Conn, error) {
+			if serveSecond.Load() {
+				return mocks[1].DialTLSHook(network, addr, config)
+			}
+			return mocks[0].DialTLSHook(network, addr, config)
+		}
+	})
+
+	p.UpdateCertificates()
+	if cert, _ := p.Cert(target); cert == nil || !cert.Equal(first.Leaf) {
+		t.Fatal("first certificate not stored")
+	}
+
+	serveSecond.Store(true)
+	p.UpdateCertificates()
+	cert, issuer := p.Cert(target)
+	if cert == nil || !cert.Equal(second.Leaf) {
+		t.Fatal("changed certificate not stored")
+	}
+	if issuer == nil || !issuer.Equal(mustParseCertificate(t, second.Certificate[1])) {
+		t.Error("issuer of the changed certificate not stored")
+	}
+}
+
+func mustParseCertificate(t *testing.T, der []byte) *x509.Certificate {
+	t.Helper()
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
@@ -0,0 +1,92 @@
+package ocsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// DryRunResult is the outcome of a dry run probe of a single OCSP server of
+// a target. If the target couldn't be probed at all, OCSPServer is empty and
+// Error describes the failure.
+type DryRunResult struct {
+	Target     string    `json:"target"`
+	OCSPServer string    `json:"ocsp_server,omitempty"`
+	CertExpiry time.Time `json:"cert_expiry,omitempty"`
+	OCSPStatus string    `json:"ocsp_status,omitempty"`
+	LatencyMs  float64   `json:"latency_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Good reports whether the OCSP server returned a "good" status.
+func (r *DryRunResult) Good() bool {
+	return r.Error == "" && r.OCSPStatus == ocspStatusString(ocsp.Good)
+}
+
+// RunDryRun downloads certificates and runs exactly one OCSP probe for every
+// OCSP server of every target.
+func (p *Probe) RunDryRun(ctx context.Context) ([]DryRunResult, error) {
+	targets := p.opts.Targets.ListEndpoints()
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets")
+	}
+
+	p.updateCertificates(nil)
+
+	var results []DryRunResult
+	for _, target := range targets {
+		requests, err := p.ocspRequestForTarget(target)
+		if err != nil {
+			results = append(results, DryRunResult{Target: target.Name, Error: err.Error()})
+			continue
+		}
+
+		cert, issuer := p.getCert(target.Key())
+
+		for server, req := range requests {
+			reqCtx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
+			res, err := ocspProbe(p.client, req.WithContext(reqCtx), issuer)
+			cancel()
+
+			result := DryRunResult{
+				Target:     target.Name,
+				OCSPServer: server,
+				CertExpiry: cert.NotAfter,
+				LatencyMs:  float64(res.spent) / float64(time.Millisecond),
+			}
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.OCSPStatus = ocspStatusString(res.OCSPStatusCode)
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// dryRun runs RunDryRun, prints results to stdout and exits the process.
+func (p *Probe) dryRun(ctx context.Context) {
+	results, err := p.RunDryRun(ctx)
+	if err != nil {
+		p.l.Criticalf("Dry run failed: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		p.l.Criticalf("Error encoding dry run results: %v", err)
+	}
+
+	for i := range results {
+		if !results[i].Good() {
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
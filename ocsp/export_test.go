@@ -0,0 +1,81 @@
+package ocsp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+)
+
+// Access to the probe internals for the tests of package ocsp_test, which
+// can't be in package ocsp as they use internal/testhelper.
+
+// ServerResult is the result of probing an OCSP server of a target.
+type ServerResult struct {
+	Total, Success, Timeouts, StaleResponses int64
+
+	// Last OCSP status, -1 if none.
+	LastStatus int
+
+	// Sum of the request latencies.
+	Latency float64
+}
+
+// RunProbe probes the OCSP servers of the target once and returns their
+// results keyed by server.
+func (p *Probe) RunProbe(ctx context.Context, target endpoint.Endpoint) (map[string]ServerResult, error) {
+	requests, err := p.ocspRequestForTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*probeResult)
+	p.runProbe(ctx, target, requests, results)
+
+	serverResults := make(map[string]ServerResult, len(results))
+	for server, result := range results {
+		serverResult := ServerResult{
+			Total:          result.total,
+			Success:        result.success,
+			Timeouts:       result.timeouts,
+			StaleResponses: result.staleResponses,
+			LastStatus:     result.lastStatus,
+		}
+		if latency, ok := result.latency.(*metrics.Float); ok {
+			serverResult.Latency = latency.Float64()
+		}
+		serverResults[server] = serverResult
+	}
+	return serverResults, nil
+}
+
+// SetCert sets the certificate of the target and its issuer.
+func (p *Probe) SetCert(target endpoint.Endpoint, cert, issuer *x509.Certificate) {
+	p.setCert(target.Key(), cert, issuer)
+}
+
+// Cert returns the certificate of the target and its issuer.
+func (p *Probe) Cert(target endpoint.Endpoint) (cert, issuer *x509.Certificate) {
+	return p.getCert(target.Key())
+}
+
+// DownloadServerCertificate downloads the certificate of the server.
+func (p *Probe) DownloadServerCertificate(server string) (*x509.Certificate, *tls.ConnectionState, error) {
+	return p.downloadServerCertificate(server, nil)
+}
+
+// UpdateCertificates downloads the certificates of all targets.
+func (p *Probe) UpdateCertificates() {
+	p.updateCertificates(nil)
+}
+
+// CertDownloadBreakerOpen returns true if the certificate download circuit
+// breaker of the target is open.
+func (p *Probe) CertDownloadBreakerOpen(target endpoint.Endpoint) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.certDownloadBreakerOpen(target.Key())
+}
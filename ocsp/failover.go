@@ -0,0 +1,213 @@
+package ocsp
+
+import (
+	"net/url"
+	"slices"
+	"time"
+)
+
+// serverFailoverState tracks the primary OCSP server of a target when only
+// the primary server is probed (primary_server_only).
+type serverFailoverState struct {
+	// OCSP server hosts in the certificate order.
+	servers []string
+
+	// Index of the current primary server and the number of its consecutive
+	// failures.
+	current  int
+	failures int32
+
+	failovers int64
+}
+
+// updateServerState refreshes the target's OCSP server list from the
+// certificate. The primary server is reset if the list changes. Must be
+// called with the probe lock held.
+func (p *Probe) updateServerState(targetKey string, ocspServers []string) {
+	var servers []string
+	for _, server := range ocspServers {
+		serverUrl, err := url.Parse(server)
+		if err != nil {
+			continue
+		}
+		servers = append(servers, serverUrl.Host)
+	}
+
+	state, ok := p.serverState[targetKey]
+	if !ok {
+		state = &serverFailoverState{}
+		p.serverState[targetKey] = state
+	}
+	if !slices.Equal(state.servers, servers) {
+		state.servers = servers
+		state.current = 0
+		state.failures = 0
+	}
+}
+
+// currentServerFor returns the current primary OCSP server of the target, or
+// an empty string if the target's OCSP servers are not known yet.
+func (p *Probe) currentServerFor(targetKey string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, ok := p.serverState[targetKey]
+	if !ok || len(state.servers) == 0 {
+		return ""
+	}
+	return state.servers[state.current]
+}
+
+// rollingSuccessRate returns the success rate of the target's OCSP server
+// over the last success_window_size probes, and false if it wasn't probed
+// yet.
+func (p *Probe) rollingSuccessRate(targetKey, server string) (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	window, ok := p.serverSuccessWindows[targetKey+"|"+server]
+	if !ok {
+		return 0, false
+	}
+	return window.rate(), true
+}
+
+// availabilityPercent returns the availability percentage of the target's
+// OCSP server over the last availability_window_size probes, and false if
+// it wasn't probed yet.
+func (p *Probe) availabilityPercent(targetKey, server string) (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	window, ok := p.serverAvailability[targetKey+"|"+server]
+	if !ok {
+		return 0, false
+	}
+	return window.rate() * 100, true
+}
+
+// serverLastResults returns the times of the last successful and failed
+// probes of the OCSP server by any target, zero if there were none.
+func (p *Probe) serverLastResults(server string) (lastSuccess, lastFailure time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.serverLastSuccess[server], p.serverLastFailure[server]
+}
+
+// updateLatencyEWMA adds the latency of a successful response to the OCSP
+// server's moving average.
+func (p *Probe) updateLatencyEWMA(server string, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	alpha := p.c.GetEwmaAlpha()
+	ewma, ok := p.serverLatencyEWMA[server]
+	if !ok {
+		ewma = p.opts.Timeout.Seconds() / 2
+	}
+	p.serverLatencyEWMA[server] = alpha*latency.Seconds() + (1-alpha)*ewma
+}
+
+// latencyEWMA returns the OCSP server's moving average latency in seconds,
+// and false if it didn't respond successfully yet.
+func (p *Probe) latencyEWMA(server string) (float64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ewma, ok := p.serverLatencyEWMA[server]
+	return ewma, ok
+}
+
+// selectFastestServer returns the OCSP server with the lowest moving average
+// latency, the first one on ties. Servers without successful responses are
+// assumed to take half of the probe timeout, so that a fast server isn't
+// preferred just because it was probed first.
+func (p *Probe) selectFastestServer(servers []string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var (
+		fastest string
+		lowest  float64
+	)
+	for _, server := range servers {
+		ewma, ok := p.serverLatencyEWMA[server]
+		if !ok {
+			ewma = p.opts.Timeout.Seconds() / 2
+		}
+		if fastest == "" || ewma < lowest {
+			fastest, lowest = server, ewma
+		}
+	}
+	return fastest
+}
+
+// needsFallback returns true if all of the target's OCSP servers have failed
+// at least fallback_threshold times in a row. Must be called with the probe
+// lock held.
+func (p *Probe) needsFallback(targetKey string, servers []string) bool {
+	if len(p.c.GetOcspFallbackUrls()) == 0 {
+		return false
+	}
+	for _, server := range servers {
+		if p.serverFailCounts[targetKey+"|"+server] < p.c.GetFallbackThreshold() {
+			return false
+		}
+	}
+	return true
+}
+
+// recordServerResult records the probe result of the target's OCSP server.
+// In primary server mode, it rotates to the next server after
+// failover_threshold consecutive failures of the primary server.
+func (p *Probe) recordServerResult(targetKey, server string, success bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if success {
+		delete(p.serverFailCounts, targetKey+"|"+server)
+		p.serverLastSuccess[server] = time.Now()
+	} else {
+		p.serverFailCounts[targetKey+"|"+server]++
+		p.serverLastFailure[server] = time.Now()
+	}
+
+	window, ok := p.serverSuccessWindows[targetKey+"|"+server]
+	if !ok {
+		window = newRollingWindow(int(p.c.GetSuccessWindowSize()))
+		p.serverSuccessWindows[targetKey+"|"+server] = window
+	}
+	window.add(success)
+
+	availability, ok := p.serverAvailability[targetKey+"|"+server]
+	if !ok {
+		availability = newRollingWindow(int(p.c.GetAvailabilityWindowSize()))
+		p.serverAvailability[targetKey+"|"+server] = availability
+	}
+	availability.add(success)
+
+	if !p.c.GetPrimaryServerOnly() {
+		return
+	}
+
+	state, ok := p.serverState[targetKey]
+	if !ok || len(state.servers) == 0 || state.servers[state.current] != server {
+		return
+	}
+
+	if success {
+		state.failures = 0
+		return
+	}
+
+	state.failures++
+	if state.failures < p.c.GetFailoverThreshold() || len(state.servers) < 2 {
+		return
+	}
+
+	state.current = (state.current + 1) % len(state.servers)
+	state.failures = 0
+	state.failovers++
+	p.l.Warningf("OCSP server %s failed %d times in a row for target %s, failing over to %s", server, p.c.GetFailoverThreshold(), targetKey, state.servers[state.current])
+}
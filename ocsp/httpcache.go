@@ -0,0 +1,108 @@
+package ocsp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// httpCacheEntry is a cached OCSP GET response with its validators.
+type httpCacheEntry struct {
+	etag, lastModified string
+	contentEncoding    string
+	body               []byte
+}
+
+// cachingTransport sends conditional OCSP GET requests for responses cached
+// from earlier requests with the same URL, i.e. for the same certificate
+// and OCSP server, and answers them from the cache on 304 Not Modified.
+type cachingTransport struct {
+	next http.RoundTripper
+	p    *Probe
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	server := req.URL.Host
+
+	t.p.mu.Lock()
+	cached, ok := t.p.httpCache[key]
+	t.p.mu.Unlock()
+
+	if ok {
+		req = req.Clone(req.Context())
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		t.p.mu.Lock()
+		t.p.http304[server]++
+		if ok {
+			t.p.httpCacheHits[server]++
+		}
+		t.p.mu.Unlock()
+		if !ok {
+			return resp, nil
+		}
+
+		_ = resp.Body.Close()
+		resp.StatusCode = http.StatusOK
+		resp.Status = strconv.Itoa(http.StatusOK) + " " + http.StatusText(http.StatusOK)
+		resp.Body = io.NopCloser(bytes.NewReader(cached.body))
+		resp.ContentLength = int64(len(cached.body))
+		resp.Header.Del("Content-Encoding")
+		if cached.contentEncoding != "" {
+			resp.Header.Set("Content-Encoding", cached.contentEncoding)
+		}
+		return resp, nil
+
+	case http.StatusOK:
+		entry := httpCacheEntry{
+			etag:            resp.Header.Get("ETag"),
+			lastModified:    resp.Header.Get("Last-Modified"),
+			contentEncoding: resp.Header.Get("Content-Encoding"),
+		}
+		if entry.etag == "" && entry.lastModified == "" {
+			return resp, nil
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		entry.body = body
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		t.p.mu.Lock()
+		t.p.httpCache[key] = entry
+		t.p.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+// httpCacheStats returns the number of 304 Not Modified responses of the
+// OCSP server, and of those answered from the cache.
+func (p *Probe) httpCacheStats(server string) (notModified, hits int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.http304[server], p.httpCacheHits[server]
+}
@@ -0,0 +1,123 @@
+package ocsp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// mockResponse is a canned response of the mock transport. A zero status
+// is 200 OK, and a non-nil err fails the request instead.
+type mockResponse struct {
+	status      int
+	contentType string
+	body        []byte
+	err         error
+}
+
+// WithMockTransport makes the probe answer OCSP requests with the responses
+// keyed by request URL, and with 404 Not Found if there's none. It must be
+// called before Init.
+func WithMockTransport(p *Probe, responses map[string]mockResponse) {
+	p.TransportHook = func(req *http.Request) (*http.Response, error) {
+		mock, ok := responses[req.URL.String()]
+		if !ok {
+			mock = mockResponse{status: http.StatusNotFound}
+		}
+		if mock.err != nil {
+			return nil, mock.err
+		}
+
+		resp := &http.Response{
+			StatusCode:    mock.status,
+			Header:        make(http.Header),
+			Body:          io.NopCloser(bytes.NewReader(mock.body)),
+			ContentLength: int64(len(mock.body)),
+			Request:       req,
+		}
+		if resp.StatusCode == 0 {
+			resp.StatusCode = http.StatusOK
+		}
+		resp.Status = http.StatusText(resp.StatusCode)
+		if mock.contentType != "" {
+			resp.Header.Set("Content-Type", mock.contentType)
+		}
+		return resp, nil
+	}
+}
+
+// newTestCertificates returns a leaf certificate with the OCSP server, and
+// its self-signed issuer with the issuer's key.
+func newTestCertificates(t *testing.T, ocspServer string) (cert, issuer *x509.Certificate, issuerKey crypto.Signer) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, issuerTemplate, issuerTemplate, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issuer, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+
+	certTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		OCSPServer:   []string{ocspServer},
+	}
+	if der, err = x509.CreateCertificate(rand.Reader, certTemplate, issuer, &key.PublicKey, key); err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, issuer, key
+}
+
+// newTestResponse returns an OCSP response with the certificate's status,
+// signed by the issuer.
+func newTestResponse(t *testing.T, cert, issuer *x509.Certificate, issuerKey crypto.Signer, status int) []byte {
+	t.Helper()
+
+	now := time.Now()
+	template := ocsp.Response{
+		Status:       status,
+		SerialNumber: cert.SerialNumber,
+		ThisUpdate:   now.Add(-time.Minute),
+		NextUpdate:   now.Add(time.Hour),
+	}
+	if status == ocsp.Revoked {
+		template.RevokedAt = now.Add(-time.Hour)
+	}
+	resp, err := ocsp.CreateResponse(issuer, issuer, template, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
@@ -0,0 +1,114 @@
+package ocsp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// oidOCSPNonce is the OCSP nonce extension (RFC 8954).
+var oidOCSPNonce = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 2}
+
+// nonceSize is the size of generated nonces, within the 1 to 32 bytes
+// allowed by RFC 8954.
+const nonceSize = 16
+
+// responseDataASN1 is the ResponseData of a basic OCSP response (RFC 6960,
+// section 4.2.1). The x/crypto/ocsp package doesn't expose the response
+// extensions, which carry the nonce.
+type responseDataASN1 struct {
+	Version            int `asn1:"optional,default:0,explicit,tag:0"`
+	RawResponderID     asn1.RawValue
+	ProducedAt         time.Time `asn1:"generalized"`
+	Responses          []asn1.RawValue
+	ResponseExtensions []pkix.Extension `asn1:"explicit,tag:1,optional"`
+}
+
+// newNonceRequest creates an OCSP request for the certificate carrying a new
+// random nonce, and returns the nonce.
+func (p *Probe) newNonceRequest(serverUrl *url.URL, cert, issuer *x509.Certificate) (*http.Request, []byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, errors.Wrap(err, "cannot generate nonce")
+	}
+
+	body, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var request ocspRequestASN1
+	if _, err := asn1.Unmarshal(body, &request); err != nil {
+		return nil, nil, err
+	}
+
+	value, err := asn1.Marshal(nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	request.TBSRequest.RequestExtensions = append(request.TBSRequest.RequestExtensions, pkix.Extension{Id: oidOCSPNonce, Value: value})
+
+	if body, err = asn1.Marshal(request); err != nil {
+		return nil, nil, err
+	}
+
+	req, err := p.newOCSPRequest(serverUrl, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, nonce, nil
+}
+
+// responseNonce returns the nonce of the OCSP response, or nil if it has
+// none. Nonces not wrapped in an OCTET STRING, as sent by some responders,
+// are returned as is.
+func responseNonce(resp *ocsp.Response) ([]byte, error) {
+	var data responseDataASN1
+	if _, err := asn1.Unmarshal(resp.TBSResponseData, &data); err != nil {
+		return nil, errors.Wrap(err, "cannot parse response data")
+	}
+
+	for _, ext := range data.ResponseExtensions {
+		if !ext.Id.Equal(oidOCSPNonce) {
+			continue
+		}
+
+		var nonce []byte
+		if rest, err := asn1.Unmarshal(ext.Value, &nonce); err != nil || len(rest) > 0 {
+			return ext.Value, nil
+		}
+		return nonce, nil
+	}
+	return nil, nil
+}
+
+// checkNonce returns errNonceMismatch if the response doesn't echo the
+// request nonce, and errNonceAbsent if the response has no nonce.
+func checkNonce(resp *ocsp.Response, nonce []byte) error {
+	echoed, err := responseNonce(resp)
+	if err != nil {
+		return err
+	}
+	if echoed == nil {
+		return errNonceAbsent
+	}
+	if !bytes.Equal(echoed, nonce) {
+		return errNonceMismatch
+	}
+	return nil
+}
+
+var (
+	errNonceAbsent   = errors.New("response has no nonce")
+	errNonceMismatch = errors.New("response nonce doesn't match request nonce")
+)
@@ -2,22 +2,35 @@ package ocsp
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"crypto"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/pem"
 	"fmt"
 	"io"
+	"maps"
 	"math/rand"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/cloudflare/cfssl/helpers"
 	"github.com/cloudprober/cloudprober/logger"
 	"github.com/cloudprober/cloudprober/metrics"
@@ -26,10 +39,34 @@ import (
 	"github.com/pkg/errors"
 
 	"golang.org/x/crypto/ocsp"
+	"golang.org/x/time/rate"
 )
 
 const (
 	defaultPort = "443"
+
+	// Maximum length of OCSP GET request URLs (RFC 6960, appendix A.1).
+	maxGetRequestLen = 255
+
+	// Size below which a response body can't be a valid OCSP response.
+	minResponseSize = 50
+
+	// Maximum size of OCSP responses in rfc5019_mode.
+	maxRFC5019ResponseSize = 4096
+)
+
+// errTLSVersionTooLow is returned when a target negotiates a TLS version
+// below min_tls_version.
+var errTLSVersionTooLow = errors.New("TLS version too low")
+
+// errEmptyChain is returned when a target presents no certificates.
+var errEmptyChain = errors.New("empty peer certificates")
+
+// errEmptyResponseBody and errResponseTooSmall are returned for OCSP response
+// bodies that are empty or too small to be parsed.
+var (
+	errEmptyResponseBody = errors.New("empty OCSP response body")
+	errResponseTooSmall  = errors.New("OCSP response too small")
 )
 
 // Probe holds aggregate information about all probe runs, per-target.
@@ -43,6 +80,15 @@ type Probe struct {
 
 	client *http.Client
 
+	// TransportHook, if set before Init, handles all OCSP HTTP requests
+	// instead of the network transport.
+	TransportHook func(*http.Request) (*http.Response, error)
+
+	// DialTLSHook, if set, connects to targets to download their
+	// certificates instead of dialing them. It's only meant for tests, and
+	// exported for internal/testhelper to serve certificates from memory.
+	DialTLSHook func(network, addr string, config *tls.Config) (*tls.Conn, error)
+
 	targets []endpoint.Endpoint
 
 	// Run counter, used to decide when to update targets or export
@@ -62,25 +108,348 @@ type Probe struct {
 	// Cancel functions for per-target probe loop
 	cancelFuncs map[string]context.CancelFunc
 
-	certs    map[string]*x509.Certificate
-	issuers  map[string]*x509.Certificate
+	certMeta map[string]*certMeta
 	requests map[string][]byte
-	sync.Mutex
+
+	// Certificates of the targets, only accessed with getCert and setCert.
+	// certLocksMu only guards the map itself, each target's certificates
+	// are guarded by their own lock.
+	certs       map[string]*targetCerts
+	certLocksMu sync.RWMutex
+
+	// Number of failed issuer certificate (AIA) fetches, per target.
+	issuerFetchFailures map[string]int64
+
+	// Number of issuer certificates found in the target's chain and fetched
+	// from AIA URLs, per target.
+	issuersFromChain map[string]int64
+	issuersFromAIA   map[string]int64
+
+	// Number of probe runs skipped because the certificate lacks the
+	// serverAuth extended key usage, per target.
+	invalidEKU map[string]int64
+
+	// Number of certificate downloads rejected for a TLS version below
+	// min_tls_version, per target.
+	tlsVersionTooLow map[string]int64
+
+	// Number of downloaded certificates not valid for the target's
+	// hostname, per target.
+	hostnameMismatches map[string]int64
+
+	// Number of certificate downloads without a stapled OCSP response for a
+	// must-staple certificate, per target.
+	mustStapleViolations map[string]int64
+
+	// Number of certificate changes with a new key, per target.
+	keyRotations map[string]int64
+
+	// Chain length violations by reason, per target.
+	chainLengthViolations map[string]*metrics.Map[int64]
+
+	// Number of downloaded chains whose certificates don't link by key
+	// identifiers, per target.
+	chainLinkageMismatches map[string]int64
+
+	// Certificate download errors by reason, per target.
+	certDownloadErrors map[string]*metrics.Map[int64]
+
+	// Certificate download circuit breakers and backoff, per target.
+	certDownloadBreaker map[string]*breakerState
+	certDownloadBackoff map[string]*backoffState
+
+	// Time of the last observed certificate change, per target.
+	certLastChanged map[string]time.Time
+
+	// Per OCSP server rate limiters, shared by all targets.
+	serverRateLimiters map[string]*rate.Limiter
+
+	// Retry-After deadlines of OCSP servers which responded with 429 Too
+	// Many Requests.
+	serverRetryAfter map[string]time.Time
+
+	// Backoff of OCSP servers which responded with tryLater.
+	serverTryLater map[string]*backoffState
+
+	// Per OCSP server locks, used to serialize requests when
+	// parallel_ocsp_servers is disabled.
+	serverMu map[string]*sync.Mutex
+
+	// Batch OCSP responses, keyed by issuer fingerprint and OCSP server.
+	batches map[string]*ocspBatch
+
+	// Primary OCSP server state, per target.
+	serverState map[string]*serverFailoverState
+
+	// Consecutive failures, keyed by target and OCSP server, and the number
+	// of probe runs using the fallback servers, per target.
+	serverFailCounts map[string]int32
+	fallbackUsed     map[string]int64
+
+	// Recent probe results for the success rate and availability, keyed by
+	// target and OCSP server.
+	serverSuccessWindows map[string]*rollingWindow
+	serverAvailability   map[string]*rollingWindow
+
+	// Times of the last successful and failed probes, per OCSP server.
+	serverLastSuccess map[string]time.Time
+	serverLastFailure map[string]time.Time
+
+	// Exponentially weighted moving average of the latency of successful
+	// responses in seconds, per OCSP server, and the OCSP server probed
+	// first in the last probe run, per target.
+	serverLatencyEWMA map[string]float64
+	selectedServer    map[string]string
+
+	// Certificate chains served by targets and their OCSP status rollup.
+	chains      map[string][]*x509.Certificate
+	chainStatus map[string]chainRollup
+
+	// Issuers of chain certificates not served by targets, keyed by AIA URL.
+	chainIssuers map[string]*x509.Certificate
+
+	// Certificates fetched from AIA URLs with their cache validators, keyed
+	// by URL, and the number of fetches answered from the cache.
+	aiaCache                     map[string]aiaCacheEntry
+	aiaCacheHits, aiaCacheMisses atomic.Int64
+
+	// Number of redirects of AIA fetches, by the fetched URL, and of
+	// redirect loops.
+	aiaRedirects     *metrics.Map[int64]
+	aiaRedirectLoops atomic.Int64
+
+	// Last OCSP status, keyed by target and OCSP server, and the time of the
+	// last status change event, keyed by target, OCSP server and new status.
+	serverStatusHistory   map[string]int
+	lastStatusChangeAlert map[string]time.Time
+
+	// Time of the last revocation webhook, keyed by target, OCSP server and
+	// certificate serial.
+	notifiedRevocations map[string]time.Time
+
+	// Latest OCSP responses, keyed by responseCacheKey.
+	responseCache map[string]*ocsp.Response
+
+	// OCSP GET responses cached for conditional requests, keyed by URL, and
+	// the number of 304 Not Modified responses and cache hits, per OCSP
+	// server.
+	httpCache              map[string]httpCacheEntry
+	http304, httpCacheHits map[string]int64
+
+	// Network to dial, "tcp4", "tcp6" or "tcp", and the number of
+	// connections made over each IP version.
+	network            string
+	ipv4Used, ipv6Used atomic.Int64
+
+	// Number of certificate updates where the target's IPs served different
+	// certificates, per target.
+	certIPInconsistencies map[string]int64
+
+	// Number of targets whose certificate update is pending.
+	certUpdateQueueDepth atomic.Int64
+
+	// Resolver of certificate downloads and the client of AIA fetches,
+	// using dns_server_addr if set.
+	resolver  *net.Resolver
+	aiaClient *http.Client
+
+	// Client of cert_source_s3_bucket, nil if certificates aren't
+	// downloaded from S3.
+	s3Client *s3.Client
+
+	// Dialer of the OCSP HTTP transport.
+	dialer *dnsCachingDialer
+
+	// Whether the OCSP client certificate for mTLS was loaded.
+	mtlsCertLoaded bool
+
+	// Root CAs loaded from root_ca_cert_dir, nil to use the system roots.
+	rootCAPool *x509.CertPool
+
+	// Guards the per-target and per-server state above, except for the
+	// certificates.
+	mu sync.Mutex
+}
+
+// targetCerts holds the certificate of a target and its issuer.
+type targetCerts struct {
+	sync.RWMutex
+	cert, issuer *x509.Certificate
 }
 
 type probeResult struct {
 	total, success, timeouts int64
-	connEvent                int64
-	latency                  metrics.LatencyValue
-	respCodes                *metrics.Map[int64]
-	ocspCodes                *metrics.Map[int64]
+
+	// Number of requests failed to resolve the OCSP server, refused by it
+	// or timed out connecting to it. Connect timeouts are also counted in
+	// timeouts.
+	dnsFailures, connRefused, connTimeouts int64
+	connEvent                              int64
+	latency                                metrics.LatencyValue
+	respCodes                              *metrics.Map[int64]
+	ocspCodes                              *metrics.Map[int64]
+
+	// OCSP statuses keyed by ocspStatusString, nil unless
+	// label_ocsp_status_as_string is set.
+	ocspStatusLabels *metrics.Map[int64]
+
+	// Number of responses echoing a different nonce, and of responses
+	// without a nonce when require_nonce_echo is set.
+	nonceMismatches   int64
+	nonceAbsentStrict int64
+
+	// Number of tryLater responses.
+	tryLater int64
+
+	// Number of POST requests retried as GET after 405 Method Not Allowed.
+	methodFallbacks int64
+
+	// Number of responses using another hash algorithm than the request.
+	hashAlgorithmMismatches int64
+
+	// Number of responses for another certificate serial than the target's.
+	serialMismatches int64
+
+	// Latency of all requests by response category, see responseCategory.
+	statusLatency map[string]metrics.LatencyValue
+
+	// Status change events not sent yet.
+	events []*metrics.EventMetrics
+
+	// Number of empty and too small response bodies.
+	emptyResponseBodies int64
+	responsesTooSmall   int64
+
+	// Archive cutoff of the last response, and the number of responses for
+	// a certificate issued before it.
+	archiveCutoff   time.Time
+	preArchiveCerts int64
+
+	// Stale responses counter and the age of the last response, in seconds.
+	staleResponses int64
+	responseAge    float64
+
+	// Number of requests delayed by the per-server rate limiter.
+	rateLimited int64
+
+	// Number of 429 Too Many Requests responses, of requests skipped until
+	// their Retry-After deadline, and of Retry-After headers used to delay
+	// requests.
+	serverRateLimited int64
+	retryAfterSkips   int64
+	retryAfterUsed    int64
+
+	// Number of requests sent over a reused connection, and of new TCP
+	// connections.
+	connReused int64
+	newConns   int64
+
+	// Latency breakdown of successful requests.
+	connectLatency metrics.LatencyValue
+	tlsLatency     metrics.LatencyValue
+	serverLatency  metrics.LatencyValue
+
+	// Distribution of the fraction of the timeout spent on requests.
+	timeoutBudgetUsed *metrics.Distribution
+
+	// Revocation time of the certificate and the time since, in seconds,
+	// zero if the last response was not revoked.
+	revokedAt         time.Time
+	revokedForSeconds float64
+
+	// SHA-256 hash of the delegated responder certificate's public key, and
+	// the number of times it changed.
+	responderSPKI        [32]byte
+	hasResponderSPKI     bool
+	responderCertChanged int64
+
+	// ID of the last responder, see responderID, and the number of times it
+	// changed.
+	responderID        string
+	responderIDChanged int64
+
+	// Delegated responder certificate violations, by violation.
+	invalidResponder *metrics.Map[int64]
+
+	// RFC 5019 profile violations in rfc5019_mode, by violation.
+	rfc5019Violations *metrics.Map[int64]
+
+	// Number of responses exceeding max_response_age_sec and violating
+	// min_next_update_sec.
+	responseTooOld     int64
+	nextUpdateImminent int64
+
+	// Number of responses with thisUpdate in the future and how far ahead
+	// of the probe's clock the last response was, in seconds.
+	futureThisUpdate int64
+	clockSkew        float64
+
+	// Time left until nextUpdate of the last response, in seconds, and the
+	// number of responses past their nextUpdate.
+	nextUpdateInSeconds float64
+	expiredResponses    int64
+
+	// Cache-Control max-age of the last response, and the number of
+	// responses with a max-age considerably shorter than their validity.
+	cacheControlMaxAge   int64
+	cacheControlTooShort int64
+
+	// Number of compressed responses.
+	compressedResponses int64
+
+	// Number of batch requests sent and their sizes.
+	batchedRequests  int64
+	requestsPerBatch *metrics.Distribution
+
+	// OCSP status of the last successful request, -1 if none.
+	lastStatus int
+
+	// OCSP status of the current probe run, -1 if the server wasn't probed
+	// or its request failed, and whether the request failed.
+	runStatus int
+	runFailed bool
 }
 
 type callResult struct {
 	HTTPStatusCode int
 	OCSPStatusCode int
 
+	// Parsed OCSP response, nil if the request failed.
+	response *ocsp.Response
+
+	// Age of the response's thisUpdate and time left until its nextUpdate,
+	// in seconds. nextUpdateInSec is zero if nextUpdate is not set.
+	responseAgeSec  float64
+	nextUpdateInSec float64
+
+	// Cache-Control max-age of the HTTP response, in seconds, -1 if not set.
+	cacheControlMaxAge int64
+
+	// Whether the HTTP response body was compressed.
+	compressed bool
+
+	// Size of the OCSP response in bytes.
+	responseSize int
+
 	spent time.Duration
+
+	// Time spent connecting, in the TLS handshake and waiting for the server
+	// to respond, zero if the phase didn't happen.
+	ConnectLatency time.Duration
+	TLSLatency     time.Duration
+	ServerLatency  time.Duration
+
+	// Fraction of the probe timeout spent on the request.
+	TimeoutBudgetUsed float64
+
+	// Retry-After deadline of the response, e.g. of a 429 Too Many Requests
+	// response, zero if none.
+	retryAfter time.Time
+
+	// Serial number of the certificate and issuer key hash from the OCSP
+	// request, in hex, set with structured_request_logging.
+	CertSerial    string
+	IssuerKeyHash string
 }
 
 // DefaultTargetsUpdateInterval defines default frequency for target updates.
@@ -95,6 +464,12 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 		return fmt.Errorf("not a ocsp probe config")
 	}
 
+	// Set before validating, validateConfig uses the config helpers.
+	p.c = c
+	if err := p.validateConfig(c); err != nil {
+		return err
+	}
+
 	p.name = name
 	p.opts = opts
 
@@ -102,10 +477,63 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 		p.l = &logger.Logger{}
 	}
 
-	p.c = c
+	p.certs = make(map[string]*targetCerts)
+	p.certMeta = make(map[string]*certMeta)
+	p.issuerFetchFailures = make(map[string]int64)
+	p.invalidEKU = make(map[string]int64)
+	p.certLastChanged = make(map[string]time.Time)
+	p.tlsVersionTooLow = make(map[string]int64)
+	p.hostnameMismatches = make(map[string]int64)
+	p.issuersFromChain = make(map[string]int64)
+	p.issuersFromAIA = make(map[string]int64)
+	p.chainLinkageMismatches = make(map[string]int64)
+	p.mustStapleViolations = make(map[string]int64)
+	p.chainLengthViolations = make(map[string]*metrics.Map[int64])
+	p.keyRotations = make(map[string]int64)
+	p.certIPInconsistencies = make(map[string]int64)
+	p.certDownloadErrors = make(map[string]*metrics.Map[int64])
+	p.certDownloadBreaker = make(map[string]*breakerState)
+	p.certDownloadBackoff = make(map[string]*backoffState)
+	p.serverRateLimiters = make(map[string]*rate.Limiter)
+	p.serverMu = make(map[string]*sync.Mutex)
+	p.serverRetryAfter = make(map[string]time.Time)
+	p.serverTryLater = make(map[string]*backoffState)
+	p.batches = make(map[string]*ocspBatch)
+	p.serverState = make(map[string]*serverFailoverState)
+	p.serverFailCounts = make(map[string]int32)
+	p.fallbackUsed = make(map[string]int64)
+	p.serverSuccessWindows = make(map[string]*rollingWindow)
+	p.serverAvailability = make(map[string]*rollingWindow)
+	p.serverLastSuccess = make(map[string]time.Time)
+	p.serverLastFailure = make(map[string]time.Time)
+	p.serverLatencyEWMA = make(map[string]float64)
+	p.selectedServer = make(map[string]string)
+	p.chains = make(map[string][]*x509.Certificate)
+	p.chainStatus = make(map[string]chainRollup)
+	p.chainIssuers = make(map[string]*x509.Certificate)
+	p.aiaCache = make(map[string]aiaCacheEntry)
+	p.responseCache = make(map[string]*ocsp.Response)
+	p.httpCache = make(map[string]httpCacheEntry)
+	p.http304 = make(map[string]int64)
+	p.httpCacheHits = make(map[string]int64)
+	p.notifiedRevocations = make(map[string]time.Time)
+	p.serverStatusHistory = make(map[string]int)
+	p.lastStatusChangeAlert = make(map[string]time.Time)
+
+	if dir := p.c.GetCacheDir(); dir != "" {
+		if err := p.loadResponseCache(dir); err != nil {
+			return fmt.Errorf("error loading OCSP response cache from %s: %v", dir, err)
+		}
+	}
 
-	p.certs = make(map[string]*x509.Certificate)
-	p.issuers = make(map[string]*x509.Certificate)
+	switch p.c.GetIpVersion() {
+	case "ipv4":
+		p.network = "tcp4"
+	case "ipv6":
+		p.network = "tcp6"
+	default:
+		p.network = "tcp"
+	}
 
 	dialer := &net.Dialer{
 		Timeout: p.opts.Timeout,
@@ -117,11 +545,67 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 		}
 	}
 
+	p.dialer = &dnsCachingDialer{
+		dialer: dialer,
+		ttl:    time.Duration(p.c.GetDnsCacheTtlSec()) * time.Second,
+	}
+
+	p.aiaRedirects = metrics.NewMap("url")
+	p.aiaClient = &http.Client{CheckRedirect: p.checkAIARedirect}
+	if addr := p.c.GetDnsServerAddr(); addr != "" {
+		p.resolver = newResolver(addr)
+		aiaDialer := &net.Dialer{Resolver: p.resolver}
+		aiaTransport := http.DefaultTransport.(*http.Transport).Clone()
+		aiaTransport.DialContext = aiaDialer.DialContext
+		p.aiaClient.Transport = aiaTransport
+	}
+
+	if p.c.GetCertSourceS3Bucket() != "" {
+		client, err := p.newS3Client()
+		if err != nil {
+			return fmt.Errorf("error creating S3 client: %v", err)
+		}
+		p.s3Client = client
+	}
+
+	// TLS config of OCSP connections only, certificates are downloaded from
+	// targets with their own config.
+	tlsConfig := &tls.Config{}
+	if dir := p.c.GetRootCaCertDir(); dir != "" {
+		pool, err := loadCertPoolFromDir(dir)
+		if err != nil {
+			return fmt.Errorf("error loading root CA certificates from %s: %v", dir, err)
+		}
+		p.rootCAPool = pool
+		tlsConfig.RootCAs = pool
+	} else if p.c.GetVerifyTargetCertificate() {
+		return fmt.Errorf("verify_target_certificate requires root_ca_cert_dir")
+	}
+	if p.c.GetOcspClientCertFile() != "" || p.c.GetOcspClientKeyFile() != "" {
+		if p.c.GetOcspClientCertFile() == "" || p.c.GetOcspClientKeyFile() == "" {
+			return fmt.Errorf("both ocsp_client_cert_file and ocsp_client_key_file must be set")
+		}
+		clientCert, err := tls.LoadX509KeyPair(p.c.GetOcspClientCertFile(), p.c.GetOcspClientKeyFile())
+		if err != nil {
+			return fmt.Errorf("error loading OCSP client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+		p.mtlsCertLoaded = true
+	}
+
 	transport := &http.Transport{
-		Proxy:               http.ProxyFromEnvironment,
-		DialContext:         dialer.DialContext,
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+		DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+			conn, err := p.dialer.DialContext(ctx, p.network, addr)
+			if err == nil {
+				p.countIPVersion(conn)
+			}
+			return conn, err
+		},
 		MaxIdleConns:        256, // http.DefaultTransport.MaxIdleConns: 100.
 		TLSHandshakeTimeout: p.opts.Timeout,
+		ForceAttemptHTTP2:   p.c.GetUseHttp2(),
 	}
 
 	if p.c.GetProxyUrl() != "" {
@@ -131,11 +615,18 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 		}
 		transport.Proxy = http.ProxyURL(proxyUrl)
 	}
+	transport.Proxy = targetProxy(transport.Proxy)
 
 	// Thread-safe
 	p.client = &http.Client{
 		Transport: transport,
 	}
+	if p.TransportHook != nil {
+		p.client.Transport = roundTripperFunc(p.TransportHook)
+	}
+	if p.c.GetUseHttpCaching() {
+		p.client.Transport = &cachingTransport{next: p.client.Transport, p: p}
+	}
 
 	p.statsExportFrequency = p.opts.StatsExportInterval.Nanoseconds() / p.opts.Interval.Nanoseconds()
 	if p.statsExportFrequency == 0 {
@@ -153,6 +644,8 @@ func (p *Probe) Init(name string, opts *options.Options) error {
 	}
 	p.l.Infof("Targets update interval: %v", p.targetsUpdateInterval)
 
+	p.registerCertExpiryHandler()
+
 	return nil
 }
 
@@ -161,7 +654,15 @@ func (p *Probe) Start(ctx context.Context, dataChan chan *metrics.EventMetrics)
 
 	defer p.wait()
 
-	p.updateCertificates()
+	if p.c.GetDryRun() {
+		p.dryRun(ctx)
+	}
+
+	em := p.startupMetrics(time.Now())
+	p.opts.LogMetrics(em)
+	dataChan <- em
+
+	p.updateCertificates(dataChan)
 	p.updateTargetsAndStartProbes(ctx, dataChan)
 
 	// Do more frequent listing of targets until we get a non-zero list of
@@ -185,7 +686,7 @@ func (p *Probe) Start(ctx context.Context, dataChan chan *metrics.EventMetrics)
 		case <-ctx.Done():
 			return
 		case <-targetsUpdateTicker.C:
-			p.updateCertificates()
+			p.updateCertificates(dataChan)
 			p.updateTargetsAndStartProbes(ctx, dataChan)
 		}
 	}
@@ -251,62 +752,392 @@ func (p *Probe) updateTargetsAndStartProbes(ctx context.Context, dataChan chan *
 	}
 }
 
-func (p *Probe) newResult() *probeResult {
-	var latencyValue metrics.LatencyValue
+func (p *Probe) newLatencyValue() metrics.LatencyValue {
 	if p.opts.LatencyDist != nil {
-		latencyValue = p.opts.LatencyDist.CloneDist()
-	} else {
-		latencyValue = metrics.NewFloat(0)
+		return p.opts.LatencyDist.CloneDist()
+	}
+	return metrics.NewFloat(0)
+}
+
+func (p *Probe) newResult() *probeResult {
+	result := &probeResult{
+		latency:            p.newLatencyValue(),
+		connectLatency:     p.newLatencyValue(),
+		tlsLatency:         p.newLatencyValue(),
+		serverLatency:      p.newLatencyValue(),
+		respCodes:          metrics.NewMap("code"),
+		ocspCodes:          metrics.NewMap("ocsp"),
+		requestsPerBatch:   metrics.NewDistribution([]float64{1, 2, 5, 10, 20, 50, 100}),
+		timeoutBudgetUsed:  metrics.NewDistribution([]float64{0.1, 0.2, 0.4, 0.6, 0.8, 0.9, 1}),
+		invalidResponder:   metrics.NewMap("violation"),
+		rfc5019Violations:  metrics.NewMap("violation"),
+		statusLatency:      make(map[string]metrics.LatencyValue),
+		lastStatus:         -1,
+		runStatus:          -1,
+		cacheControlMaxAge: -1,
 	}
-	return &probeResult{
-		latency:   latencyValue,
-		respCodes: metrics.NewMap("code"),
-		ocspCodes: metrics.NewMap("ocsp"),
+	if p.c.GetLabelOcspStatusAsString() {
+		result.ocspStatusLabels = metrics.NewMap("ocsp")
 	}
+	return result
 }
 
-func (p *Probe) runProbe(ctx context.Context, target endpoint.Endpoint, requests map[string]*http.Request, results map[string]*probeResult) {
-	issuer, ok := p.issuers[target.Key()]
-	if !ok {
-		return
+func (p *Probe) runProbe(ctx context.Context, target endpoint.Endpoint, requests map[string]*http.Request, results map[string]*probeResult) (dispatched int64) {
+	for _, result := range results {
+		result.runStatus, result.runFailed = -1, false
+	}
+
+	cert, issuer := p.getCert(target.Key())
+	if issuer == nil {
+		return 0
+	}
+
+	var primary string
+	if p.c.GetPrimaryServerOnly() {
+		primary = p.currentServerFor(target.Key())
+	}
+
+	servers := slices.Sorted(maps.Keys(requests))
+	if !p.c.GetParallelOcspServers() && len(servers) > 1 {
+		fastest := p.selectFastestServer(servers)
+		servers = slices.DeleteFunc(servers, func(server string) bool { return server == fastest })
+		servers = slices.Insert(servers, 0, fastest)
+
+		p.mu.Lock()
+		p.selectedServer[target.Key()] = fastest
+		p.mu.Unlock()
 	}
 
-	for server, req := range requests {
+	for _, server := range servers {
 		var (
 			ok     bool
 			result *probeResult
 		)
+		req := requests[server]
 
-		ctx, cancel := context.WithTimeout(ctx, p.opts.Timeout)
-		res, err := ocspProbe(p.client, req.WithContext(ctx), issuer)
-		cancel()
+		if primary != "" && server != primary {
+			continue
+		}
 
 		if result, ok = results[server]; !ok {
 			results[server] = p.newResult()
 			result = results[server]
 		}
 
+		if p.retryAfterPending(server) {
+			result.retryAfterSkips++
+			continue
+		}
+		if p.tryLaterPending(server) {
+			continue
+		}
+
+		if limiter := p.rateLimiter(server); limiter != nil && !limiter.Allow() {
+			result.rateLimited++
+			if err := limiter.Wait(ctx); err != nil {
+				return dispatched
+			}
+		}
+
+		var mu *sync.Mutex
+		if !p.c.GetParallelOcspServers() {
+			mu = p.serverLock(server)
+			mu.Lock()
+		}
+
+		timing := &requestTiming{}
+		traceCtx := httptrace.WithClientTrace(ctx, timing.clientTrace())
+
+		var (
+			res   *callResult
+			err   error
+			nonce []byte
+		)
+		if p.c.GetBatchOcspRequests() {
+			var (
+				batchSize int
+				sent      bool
+			)
+			res, batchSize, sent, err = p.batchProbe(traceCtx, target, req.URL)
+			if sent {
+				dispatched++
+				result.batchedRequests++
+				result.requestsPerBatch.AddSample(float64(batchSize))
+			}
+		} else {
+			if p.useNonce() {
+				var nonceReq *http.Request
+				if nonceReq, nonce, err = p.newNonceRequest(req.URL, cert, issuer); err != nil {
+					p.l.Warningf("Target: %s, URL: %s, cannot create OCSP request with nonce: %v", target.Name, req.URL.String(), err)
+				} else {
+					req = nonceReq
+				}
+			}
+
+			reqCtx, cancel := context.WithTimeout(traceCtx, p.opts.Timeout)
+			res, err = ocspProbe(p.client, req.WithContext(reqCtx), issuer)
+			cancel()
+			dispatched++
+
+			if p.c.GetFallbackToGet() && req.Method == http.MethodPost && res.HTTPStatusCode == http.StatusMethodNotAllowed {
+				if getReq, getErr := getFallbackRequest(req); getErr != nil {
+					p.l.Warningf("Target: %s, URL: %s, cannot create GET fallback request: %v", target.Name, req.URL.String(), getErr)
+				} else {
+					p.l.Infof("Target: %s, URL: %s, POST not allowed, falling back to GET", target.Name, req.URL.String())
+					result.methodFallbacks++
+					reqCtx, cancel := context.WithTimeout(traceCtx, p.opts.Timeout)
+					res, err = ocspProbe(p.client, getReq.WithContext(reqCtx), issuer)
+					cancel()
+					dispatched++
+				}
+			}
+		}
+
+		if mu != nil {
+			mu.Unlock()
+		}
+
+		if timing.reused {
+			result.connReused++
+		}
+		result.newConns += timing.newConns
+		if res != nil {
+			timing.apply(res)
+			res.TimeoutBudgetUsed = res.spent.Seconds() / p.opts.Timeout.Seconds()
+			result.timeoutBudgetUsed.AddSample(res.TimeoutBudgetUsed)
+		}
+
 		result.total++
+		result.runFailed = true
+
+		if res != nil {
+			category := responseCategory(res.HTTPStatusCode)
+			latency, ok := result.statusLatency[category]
+			if !ok {
+				latency = p.newLatencyValue()
+				result.statusLatency[category] = latency
+			}
+			latency.AddFloat64(res.spent.Seconds() / p.opts.LatencyUnit.Seconds())
+		}
+
+		if res != nil && res.HTTPStatusCode == http.StatusTooManyRequests {
+			result.serverRateLimited++
+			if !res.retryAfter.IsZero() {
+				p.mu.Lock()
+				p.serverRetryAfter[server] = res.retryAfter
+				p.mu.Unlock()
+			}
+		}
+
+		if res != nil && p.c.GetStructuredRequestLogging() {
+			res.CertSerial, res.IssuerKeyHash = requestCertID(req)
+			if p.c.GetLogFormat() != "json" {
+				p.l.Infof("Target: %s, URL: %s, OCSP request cert_serial=%s issuer_key_hash=%s http_status=%d ocsp_status=%d", target.Name, req.URL.String(), res.CertSerial, res.IssuerKeyHash, res.HTTPStatusCode, res.OCSPStatusCode)
+			}
+		}
+		if res != nil && p.c.GetLogFormat() == "json" {
+			p.logRunEntry(target, server, res)
+		}
+
+		var respErr ocsp.ResponseError
+		tryLater := errors.As(err, &respErr) && respErr.Status == ocsp.TryLater
+		if tryLater {
+			result.tryLater++
+		}
+		if res != nil && !res.retryAfter.IsZero() && (tryLater || res.HTTPStatusCode == http.StatusTooManyRequests) {
+			p.l.Debugf("Target: %s, URL: %s, Retry-After in %s", target.Name, req.URL.String(), time.Until(res.retryAfter).Round(time.Second))
+			result.retryAfterUsed++
+		}
+		if res != nil && res.HTTPStatusCode != 0 {
+			p.updateTryLaterBackoff(server, tryLater, res.retryAfter)
+		}
 
 		if err != nil {
+			p.recordServerResult(target.Key(), server, false)
 			if isClientTimeout(err) {
 				p.l.Warning("Target:", target.Name, ", URL:", req.URL.String(), ", http.doHTTPRequest: timeout error: ", err.Error())
 				result.timeouts++
-				return
+				if !timing.gotConn && !isDNSError(err) {
+					result.connTimeouts++
+				}
+				continue
+			}
+			switch {
+			case isDNSError(err):
+				result.dnsFailures++
+			case errors.Is(err, syscall.ECONNREFUSED):
+				result.connRefused++
+			case errors.Is(err, errEmptyResponseBody):
+				result.emptyResponseBodies++
+			case errors.Is(err, errResponseTooSmall):
+				result.responsesTooSmall++
 			}
 			p.l.Warning("1 Target:", target.Name, ", URL:", req.URL.String(), ", http.doHTTPRequest: ", err.Error())
-			return
+			continue
+		}
+
+		if nonce != nil {
+			switch err := checkNonce(res.response, nonce); {
+			case errors.Is(err, errNonceAbsent):
+				if p.c.GetRequireNonceEcho() {
+					p.l.Warningf("Target: %s, URL: %s, OCSP response omits the request nonce", target.Name, req.URL.String())
+					result.nonceAbsentStrict++
+					p.recordServerResult(target.Key(), server, false)
+					continue
+				}
+			case err != nil:
+				p.l.Warningf("Target: %s, URL: %s, invalid OCSP response nonce: %v", target.Name, req.URL.String(), err)
+				result.nonceMismatches++
+				p.recordServerResult(target.Key(), server, false)
+				continue
+			}
+		}
+
+		// ocsp.ParseResponse doesn't match the response to the certificate,
+		// and buggy batch responders were seen answering for other serials.
+		if res.response.SerialNumber == nil || res.response.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+			p.l.Errorf("Target: %s, URL: %s, OCSP response is for serial %x instead of %x", target.Name, req.URL.String(), res.response.SerialNumber, cert.SerialNumber)
+			result.serialMismatches++
+			p.recordServerResult(target.Key(), server, false)
+			continue
 		}
 
+		// Use the age measured when the response was parsed, so that it's
+		// consistent with the time until nextUpdate.
+		age := time.Duration(res.responseAgeSec * float64(time.Second))
+		result.responseAge = res.responseAgeSec
+
+		result.clockSkew = 0
+		if age < 0 {
+			result.clockSkew = -age.Seconds()
+			if maxSkew := time.Duration(p.c.GetMaxClockSkewSec()) * time.Second; -age > maxSkew {
+				p.l.Warningf("Target: %s, URL: %s, OCSP response thisUpdate %s is in the future", target.Name, req.URL.String(), res.response.ThisUpdate)
+				result.futureThisUpdate++
+			}
+		}
+		if maxAge := time.Duration(p.c.GetMaxThisUpdateAgeSec()) * time.Second; maxAge > 0 && age > maxAge {
+			p.l.Warningf("Target: %s, URL: %s, stale OCSP response: thisUpdate %s is older than %s", target.Name, req.URL.String(), res.response.ThisUpdate, maxAge)
+			result.staleResponses++
+			if p.c.GetFailOnStaleResponse() {
+				p.recordServerResult(target.Key(), server, false)
+				continue
+			}
+		}
+
+		if p.c.GetRfc5019Mode() {
+			if res.response.NextUpdate.IsZero() {
+				p.l.Warningf("Target: %s, URL: %s, OCSP response lacks nextUpdate required by RFC 5019", target.Name, req.URL.String())
+				result.rfc5019Violations.IncKey("missing_next_update")
+			}
+			if res.responseSize > maxRFC5019ResponseSize {
+				p.l.Warningf("Target: %s, URL: %s, OCSP response of %d bytes exceeds %d bytes allowed by RFC 5019", target.Name, req.URL.String(), res.responseSize, maxRFC5019ResponseSize)
+				result.rfc5019Violations.IncKey("response_too_large")
+			}
+		}
+
+		// Requests always identify certificates with SHA-1 hashes, some
+		// responders answer with other hashes regardless.
+		if res.response.IssuerHash != crypto.SHA1 {
+			p.l.Warningf("Target: %s, URL: %s, OCSP response uses %s instead of the requested %s", target.Name, req.URL.String(), res.response.IssuerHash, crypto.SHA1)
+			result.hashAlgorithmMismatches++
+		}
+
+		if maxAge := p.c.GetMaxResponseAgeSec(); maxAge > 0 && res.responseAgeSec > float64(maxAge) {
+			result.responseTooOld++
+		}
+		if !res.response.NextUpdate.IsZero() {
+			result.nextUpdateInSeconds = res.nextUpdateInSec
+			if res.nextUpdateInSec < 0 {
+				p.l.Warningf("Target: %s, URL: %s, expired OCSP response: nextUpdate %s", target.Name, req.URL.String(), res.response.NextUpdate)
+				result.expiredResponses++
+			}
+		}
+		if res.compressed {
+			result.compressedResponses++
+		}
+
+		result.cacheControlMaxAge = res.cacheControlMaxAge
+		if res.cacheControlMaxAge >= 0 && !res.response.NextUpdate.IsZero() {
+			window := res.response.NextUpdate.Sub(res.response.ThisUpdate).Seconds()
+			if float64(res.cacheControlMaxAge) < window*0.9 {
+				result.cacheControlTooShort++
+			}
+		}
+		if minNext := p.c.GetMinNextUpdateSec(); minNext > 0 && !res.response.NextUpdate.IsZero() && res.nextUpdateInSec < float64(minNext) {
+			result.nextUpdateImminent++
+		}
+
+		p.recordServerResult(target.Key(), server, true)
+		p.cacheResponse(target.Key(), server, res.response)
 		result.success++
+		result.lastStatus = res.OCSPStatusCode
+		result.runStatus, result.runFailed = res.OCSPStatusCode, false
+		if em := p.recordStatus(time.Now(), target, server, res.OCSPStatusCode); em != nil {
+			result.events = append(result.events, em)
+		}
+
+		if id := responderID(res.response); id != "" {
+			if result.responderID != "" && id != result.responderID {
+				p.l.Infof("Target: %s, URL: %s, OCSP responder changed: %s -> %s", target.Name, req.URL.String(), result.responderID, id)
+				result.responderIDChanged++
+			}
+			result.responderID = id
+		}
+
+		if responderCert := res.response.Certificate; responderCert != nil {
+			spki := sha256.Sum256(responderCert.RawSubjectPublicKeyInfo)
+			if result.hasResponderSPKI && spki != result.responderSPKI {
+				p.l.Infof("Target: %s, URL: %s, OCSP responder certificate changed", target.Name, req.URL.String())
+				result.responderCertChanged++
+			}
+			result.responderSPKI, result.hasResponderSPKI = spki, true
+
+			for _, violation := range validateOCSPResponderCert(responderCert, issuer) {
+				p.l.Warningf("Target: %s, URL: %s, invalid delegated OCSP responder certificate: %s", target.Name, req.URL.String(), violation)
+				result.invalidResponder.IncKey(violation)
+			}
+		}
+
+		result.archiveCutoff = time.Time{}
+		if cutoff, ok := extractArchiveCutoff(res.response); ok {
+			result.archiveCutoff = cutoff
+			if cert != nil && cert.NotBefore.Before(cutoff) {
+				p.l.Warningf("Target: %s, URL: %s, certificate issued before the OCSP archive cutoff %s, its revocation data may be unreliable", target.Name, req.URL.String(), cutoff)
+				result.preArchiveCerts++
+			}
+		}
+
+		result.revokedAt, result.revokedForSeconds = time.Time{}, 0
+		if res.OCSPStatusCode == ocsp.Revoked && !res.response.RevokedAt.IsZero() {
+			result.revokedAt = res.response.RevokedAt
+			result.revokedForSeconds = time.Since(res.response.RevokedAt).Seconds()
+		}
+
+		if res.OCSPStatusCode == ocsp.Revoked && p.c.GetRevocationWebhookUrl() != "" {
+			p.waitGroup.Add(1)
+			go func(server string, resp *ocsp.Response) {
+				defer p.waitGroup.Done()
+				p.sendRevocationWebhook(ctx, target, server, resp)
+			}(server, res.response)
+		}
 
 		result.respCodes.IncKey(strconv.FormatInt(int64(res.HTTPStatusCode), 10))
-		result.ocspCodes.IncKey(strconv.FormatInt(int64(res.OCSPStatusCode), 10))
+		result.countOCSPStatus(res.OCSPStatusCode)
 		result.latency.AddFloat64(res.spent.Seconds() / p.opts.LatencyUnit.Seconds())
+		p.updateLatencyEWMA(server, res.spent)
+		if res.ConnectLatency > 0 {
+			result.connectLatency.AddFloat64(res.ConnectLatency.Seconds() / p.opts.LatencyUnit.Seconds())
+		}
+		if res.TLSLatency > 0 {
+			result.tlsLatency.AddFloat64(res.TLSLatency.Seconds() / p.opts.LatencyUnit.Seconds())
+		}
+		if res.ServerLatency > 0 {
+			result.serverLatency.AddFloat64(res.ServerLatency.Seconds() / p.opts.LatencyUnit.Seconds())
+		}
 
 	}
 
-	return
+	return dispatched
 }
 
 func (p *Probe) startForTarget(ctx context.Context, target endpoint.Endpoint, dataChan chan *metrics.EventMetrics) {
@@ -315,11 +1146,43 @@ func (p *Probe) startForTarget(ctx context.Context, target endpoint.Endpoint, da
 	// We use this counter to decide when to export stats.
 	var runCnt int64
 
+	// Number of probe runs without OCSP requests, as they couldn't be
+	// created.
+	var requestCreationErrors int64
+
+	// Number of OCSP HTTP requests sent in the last probe run, lower than
+	// the number of OCSP servers if some were skipped or answered by another
+	// target's batch request.
+	var dispatched int64
+
+	exportFrequency := p.targetExportFrequency(target)
+
 	for _, al := range p.opts.AdditionalLabels {
 		al.UpdateForTarget(target, target.IP.String(), target.Port)
 	}
 
+	// Make the target available to the transport for per-target proxies.
+	ctx = context.WithValue(ctx, targetContextKey{}, target)
+
 	results := make(map[string]*probeResult)
+	chainResults := make(map[chainKey]*probeResult)
+
+	// Seed results with cached responses, so that the status is known even
+	// before the first OCSP round-trip succeeds.
+	if cert, _ := p.getCert(target.Key()); cert != nil {
+		for _, server := range cert.OCSPServer {
+			serverUrl, err := url.Parse(server)
+			if err != nil {
+				continue
+			}
+			if resp := p.cachedResponse(target.Key(), serverUrl.Host); resp != nil {
+				result := p.newResult()
+				result.lastStatus = resp.Status
+				result.responseAge = time.Since(resp.ThisUpdate).Seconds()
+				results[serverUrl.Host] = result
+			}
+		}
+	}
 
 	ticker := time.NewTicker(p.opts.Interval)
 	defer ticker.Stop()
@@ -330,77 +1193,480 @@ func (p *Probe) startForTarget(ctx context.Context, target endpoint.Endpoint, da
 			return
 		}
 
+		// Keep going if requests can't be created, certificates may become
+		// available after the next update and the failure is still exported.
 		requests, err := p.ocspRequestForTarget(target)
 		if err != nil {
 			p.l.Errorf("cannot create OCSP requests for target %s: %s", target.Name, err.Error())
-			return
+			requestCreationErrors++
+		} else if p.c.GetRequireServerAuthEku() && !p.hasServerAuthEKU(target) {
+			p.l.Warningf("certificate of target %s lacks serverAuth extended key usage, skipping OCSP probe", target.Name)
+			p.mu.Lock()
+			p.invalidEKU[target.Key()]++
+			p.mu.Unlock()
+		} else if p.c.GetEnforceMustStaple() && p.mustStapleViolated(target) {
+			p.l.Warningf("target %s doesn't staple OCSP responses for its must-staple certificate, skipping OCSP probe", target.Name)
+		} else {
+			dispatched = p.runProbe(ctx, target, requests, results)
+		}
+
+		// Send status change events right away, not with the stats.
+		for _, result := range results {
+			for _, em := range result.events {
+				p.opts.LogMetrics(em)
+				dataChan <- em
+			}
+			result.events = nil
 		}
 
-		p.runProbe(ctx, target, requests, results)
+		if p.c.GetCheckChainOcsp() {
+			p.runChainProbe(ctx, target, results, chainResults)
+		}
 
 		// Export stats if it's the time to do so.
 		runCnt++
-		if (runCnt % p.statsExportFrequency) == 0 {
+		if (runCnt % exportFrequency) == 0 {
 			for server, result := range results {
-				em := metrics.NewEventMetrics(ts).
-					AddMetric("total", metrics.NewInt(result.total)).
-					AddMetric("success", metrics.NewInt(result.success)).
-					AddMetric("latency", result.latency).
-					AddMetric("timeouts", metrics.NewInt(result.timeouts)).
-					AddMetric("resp-code", result.respCodes).
-					AddMetric("ocsp-code", result.ocspCodes).
-					AddLabel("ptype", "ocsp").
-					AddLabel("probe", p.name).
-					AddLabel("ocsp-server", server).
-					AddLabel("dst", target.Name)
-				em.LatencyUnit = p.opts.LatencyUnit
-				for _, al := range p.opts.AdditionalLabels {
-					em.AddLabel(al.KeyValueForTarget(target))
+				em := p.serverMetrics(ts, target, server, result)
+				if rate, ok := p.rollingSuccessRate(target.Key(), server); ok {
+					em.AddMetric("ocsp_rolling_success_rate", metrics.NewFloat(rate))
+				}
+				if availability, ok := p.availabilityPercent(target.Key(), server); ok {
+					em.AddMetric("ocsp_availability_percent", metrics.NewFloat(availability))
+				}
+				if ewma, ok := p.latencyEWMA(server); ok {
+					em.AddMetric("ocsp_latency_ewma", metrics.NewFloat(ewma/p.opts.LatencyUnit.Seconds()))
+				}
+				lastSuccess, lastFailure := p.serverLastResults(server)
+				if !lastSuccess.IsZero() {
+					em.AddMetric("ocsp_server_last_success_unix", metrics.NewInt(lastSuccess.Unix()))
+				}
+				if !lastFailure.IsZero() {
+					em.AddMetric("ocsp_server_last_failure_unix", metrics.NewInt(lastFailure.Unix()))
+				}
+				if p.c.GetCheckChainOcsp() {
+					em.AddLabel("cert_depth", "0")
+				}
+				p.opts.LogMetrics(em)
+				dataChan <- em
+
+				for category, latency := range result.statusLatency {
+					em := p.categoryLatencyMetrics(ts, target, server, category, latency)
+					p.opts.LogMetrics(em)
+					dataChan <- em
 				}
+			}
+
+			for key, result := range chainResults {
+				em := p.serverMetrics(ts, target, key.server, result).
+					AddLabel("cert_depth", strconv.Itoa(key.depth))
 				p.opts.LogMetrics(em)
 				dataChan <- em
 			}
+
+			em := p.targetMetrics(ts, target, requestCreationErrors).
+				AddMetric("ocsp_requests_dispatched", metrics.NewInt(dispatched))
+			p.opts.LogMetrics(em)
+			dataChan <- em
 		}
 	}
 }
 
-func (p *Probe) gapBetweenTargets() time.Duration {
-	interTargetGap := time.Duration(p.c.GetIntervalBetweenTargetsMsec()) * time.Millisecond
-
-	// If not configured by user, determine based on probe interval and number of
-	// targets.
-	if interTargetGap == 0 {
-		// Use 1/10th of the probe interval to spread out target groroutines.
-		interTargetGap = p.opts.Interval / time.Duration(10*len(p.targets))
-	}
-
-	return interTargetGap
+// startupMetrics returns metrics describing the probe setup.
+func (p *Probe) startupMetrics(ts time.Time) *metrics.EventMetrics {
+	em := metrics.NewEventMetrics(ts).
+		AddMetric("mtls_cert_loaded", metrics.NewInt(boolToInt(p.mtlsCertLoaded))).
+		AddLabel("ptype", "ocsp").
+		AddLabel("probe", p.name)
+	em.Kind = metrics.GAUGE
+	return em
 }
 
-// Create OCSP http requests, one per OSCP server specified in certificate
-func (p *Probe) ocspRequestForTarget(target endpoint.Endpoint) (map[string]*http.Request, error) {
-	p.Lock()
-	defer p.Unlock()
-
-	var err error
-
-	cert, ok := p.certs[target.Key()]
-	if !ok || cert == nil {
-		return nil, fmt.Errorf("no domain certificate for target %s", target.Key())
+// targetExportFrequency returns how often to export the target's metrics (in
+// probe counts). The "stats_export_interval_sec" target label overrides the
+// probe's stats export interval.
+func (p *Probe) targetExportFrequency(target endpoint.Endpoint) int64 {
+	value, ok := target.Labels["stats_export_interval_sec"]
+	if !ok {
+		return p.statsExportFrequency
 	}
 
-	if len(cert.OCSPServer) < 1 {
-		return nil, fmt.Errorf("no OCSP servers defined for target %s", target.Key())
+	sec, err := strconv.ParseFloat(value, 64)
+	if err != nil || sec <= 0 {
+		p.l.Warningf("Target: %s, invalid stats_export_interval_sec label: %q", target.Name, value)
+		return p.statsExportFrequency
 	}
 
-	issuer := p.issuers[target.Key()]
-	if !ok || issuer == nil {
-		return nil, fmt.Errorf("no issuer certificate for target %s", target.Key())
+	if freq := int64(sec / p.opts.Interval.Seconds()); freq > 1 {
+		return freq
 	}
+	return 1
+}
 
-	body, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA1})
-	if err != nil {
-		return nil, err
+// serverMetrics returns metrics of the OCSP server for the target.
+func (p *Probe) serverMetrics(ts time.Time, target endpoint.Endpoint, server string, result *probeResult) *metrics.EventMetrics {
+	em := metrics.NewEventMetrics(ts).
+		AddMetric("total", metrics.NewInt(result.total)).
+		AddMetric("success", metrics.NewInt(result.success)).
+		AddMetric("latency", result.latency).
+		AddMetric("ocsp_connect_latency", result.connectLatency).
+		AddMetric("ocsp_tls_latency", result.tlsLatency).
+		AddMetric("ocsp_server_latency", result.serverLatency).
+		AddMetric("ocsp_timeout_budget_used_fraction", result.timeoutBudgetUsed).
+		AddMetric("timeouts", metrics.NewInt(result.timeouts)).
+		AddMetric("dns_resolution_failed_total", metrics.NewInt(result.dnsFailures)).
+		AddMetric("connection_refused_total", metrics.NewInt(result.connRefused)).
+		AddMetric("connection_timeout_total", metrics.NewInt(result.connTimeouts)).
+		AddMetric("empty_response_body_total", metrics.NewInt(result.emptyResponseBodies)).
+		AddMetric("response_too_small_total", metrics.NewInt(result.responsesTooSmall)).
+		AddMetric("resp-code", result.respCodes).
+		AddMetric("ocsp-code", result.ocspCodes).
+		AddMetric("stale_response_total", metrics.NewInt(result.staleResponses)).
+		AddMetric("ocsp_response_age_seconds", metrics.NewFloat(result.responseAge)).
+		AddMetric("rate_limited_total", metrics.NewInt(result.rateLimited)).
+		AddMetric("server_rate_limited_total", metrics.NewInt(result.serverRateLimited)).
+		AddMetric("rate_limited_skip_total", metrics.NewInt(result.retryAfterSkips)).
+		AddMetric("retry_after_header_used_total", metrics.NewInt(result.retryAfterUsed)).
+		AddMetric("try_later_total", metrics.NewInt(result.tryLater)).
+		AddMetric("method_fallback_total", metrics.NewInt(result.methodFallbacks)).
+		AddMetric("hash_algorithm_mismatch_total", metrics.NewInt(result.hashAlgorithmMismatches)).
+		AddMetric("serial_mismatch_total", metrics.NewInt(result.serialMismatches)).
+		AddMetric("currently_backed_off", metrics.NewInt(boolToInt(p.tryLaterPending(server)))).
+		AddMetric("connection_reused_total", metrics.NewInt(result.connReused)).
+		AddMetric("new_tcp_connections_total", metrics.NewInt(result.newConns)).
+		AddMetric("response_too_old_total", metrics.NewInt(result.responseTooOld)).
+		AddMetric("next_update_imminent_total", metrics.NewInt(result.nextUpdateImminent)).
+		AddMetric("ocsp_seconds_until_next_update", metrics.NewFloat(result.nextUpdateInSeconds)).
+		AddMetric("expired_response_total", metrics.NewInt(result.expiredResponses)).
+		AddMetric("ocsp_cache_control_max_age_seconds", metrics.NewInt(result.cacheControlMaxAge)).
+		AddMetric("cache_control_too_short_total", metrics.NewInt(result.cacheControlTooShort)).
+		AddMetric("compressed_response_total", metrics.NewInt(result.compressedResponses)).
+		AddMetric("future_this_update_total", metrics.NewInt(result.futureThisUpdate)).
+		AddMetric("clock_skew_seconds", metrics.NewFloat(result.clockSkew)).
+		AddMetric("responder_id_changed_total", metrics.NewInt(result.responderIDChanged)).
+		AddLabel("ptype", "ocsp").
+		AddLabel("probe", p.name).
+		AddLabel("ocsp-server", server).
+		AddLabel("dst", target.Name)
+	if result.responderID != "" {
+		em.AddLabel("responder_id", result.responderID)
+	}
+	if p.c.GetRfc5019Mode() {
+		em.AddMetric("rfc5019_violation_total", result.rfc5019Violations)
+	}
+	if result.hasResponderSPKI {
+		em.AddMetric("responder_cert_changed_total", metrics.NewInt(result.responderCertChanged)).
+			AddMetric("invalid_delegated_responder_total", result.invalidResponder).
+			AddLabel("responder_spki_hash", hex.EncodeToString(result.responderSPKI[:]))
+	}
+	if !result.archiveCutoff.IsZero() {
+		em.AddMetric("ocsp_archive_cutoff_unix", metrics.NewInt(result.archiveCutoff.Unix())).
+			AddMetric("pre_archive_cert_total", metrics.NewInt(result.preArchiveCerts))
+	}
+	if !result.revokedAt.IsZero() {
+		em.AddMetric("cert_revoked_duration_seconds", metrics.NewFloat(result.revokedForSeconds)).
+			AddMetric("cert_revoked_at_unix", metrics.NewInt(result.revokedAt.Unix()))
+	}
+	if result.ocspStatusLabels != nil {
+		em.AddMetric("ocsp-status", result.ocspStatusLabels)
+	}
+	if p.c.GetUseHttpCaching() {
+		notModified, hits := p.httpCacheStats(server)
+		em.AddMetric("http_304_total", metrics.NewInt(notModified)).
+			AddMetric("http_cache_hit_total", metrics.NewInt(hits))
+	}
+	if result.total > 0 {
+		em.AddMetric("connection_reuse_rate", metrics.NewFloat(1-float64(result.newConns)/float64(result.total)))
+	}
+	if p.useNonce() {
+		em.AddMetric("nonce_mismatch_total", metrics.NewInt(result.nonceMismatches))
+		if p.c.GetRequireNonceEcho() {
+			em.AddMetric("nonce_absent_strict_mode_total", metrics.NewInt(result.nonceAbsentStrict))
+		}
+	}
+	if p.c.GetBatchOcspRequests() {
+		em.AddMetric("batched_request_total", metrics.NewInt(result.batchedRequests)).
+			AddMetric("requests_per_batch", result.requestsPerBatch)
+	}
+	em.LatencyUnit = p.opts.LatencyUnit
+	for _, al := range p.opts.AdditionalLabels {
+		em.AddLabel(al.KeyValueForTarget(target))
+	}
+	return em
+}
+
+// categoryLatencyMetrics returns the latency of the OCSP server's responses
+// of the category for the target.
+func (p *Probe) categoryLatencyMetrics(ts time.Time, target endpoint.Endpoint, server, category string, latency metrics.LatencyValue) *metrics.EventMetrics {
+	em := metrics.NewEventMetrics(ts).
+		AddMetric("response_latency", latency).
+		AddLabel("ptype", "ocsp").
+		AddLabel("probe", p.name).
+		AddLabel("ocsp-server", server).
+		AddLabel("dst", target.Name).
+		AddLabel("response_category", category)
+	em.LatencyUnit = p.opts.LatencyUnit
+	for _, al := range p.opts.AdditionalLabels {
+		em.AddLabel(al.KeyValueForTarget(target))
+	}
+	return em
+}
+
+// responseCategory returns the category of the HTTP status code, e.g. "2xx",
+// or "network_error" if there was no response.
+func responseCategory(statusCode int) string {
+	if statusCode == 0 {
+		return "network_error"
+	}
+	return fmt.Sprintf("%dxx", statusCode/100)
+}
+
+// targetMetrics returns metrics describing the target as a whole rather than
+// a single OCSP server, e.g. its current certificate. Certificate metrics are
+// omitted if no certificate has been downloaded for the target yet, which is
+// reported by cert_available.
+func (p *Probe) targetMetrics(ts time.Time, target endpoint.Endpoint, requestCreationErrors int64) *metrics.EventMetrics {
+	cert, _ := p.getCert(target.Key())
+
+	p.mu.Lock()
+	meta, ok := p.certMeta[target.Key()]
+	issuerFetchFailures := p.issuerFetchFailures[target.Key()]
+	issuersFromChain := p.issuersFromChain[target.Key()]
+	issuersFromAIA := p.issuersFromAIA[target.Key()]
+	invalidEKU := p.invalidEKU[target.Key()]
+	tlsVersionTooLow := p.tlsVersionTooLow[target.Key()]
+	hostnameMismatches := p.hostnameMismatches[target.Key()]
+	chainLinkageMismatches := p.chainLinkageMismatches[target.Key()]
+	mustStapleViolations := p.mustStapleViolations[target.Key()]
+	keyRotations := p.keyRotations[target.Key()]
+	certIPInconsistencies := p.certIPInconsistencies[target.Key()]
+	certDownloadErrors := p.certDownloadErrors[target.Key()]
+	chainLengthViolations := p.chainLengthViolations[target.Key()]
+	breakerOpen := p.certDownloadBreakerOpen(target.Key())
+	var backoff time.Duration
+	if state, ok := p.certDownloadBackoff[target.Key()]; ok {
+		backoff = state.delay
+	}
+	lastChanged := p.certLastChanged[target.Key()]
+	chainStatus, hasChainStatus := p.chainStatus[target.Key()]
+	fallbackUsed := p.fallbackUsed[target.Key()]
+	selectedServer := p.selectedServer[target.Key()]
+	var failovers int64
+	if state, ok := p.serverState[target.Key()]; ok {
+		failovers = state.failovers
+	}
+	p.mu.Unlock()
+
+	em := metrics.NewEventMetrics(ts).
+		AddMetric("cert_available", metrics.NewInt(boolToInt(cert != nil))).
+		AddMetric("request_creation_error_total", metrics.NewInt(requestCreationErrors)).
+		AddMetric("issuer_fetch_failed_total", metrics.NewInt(issuerFetchFailures)).
+		AddMetric("issuer_from_chain_total", metrics.NewInt(issuersFromChain)).
+		AddMetric("issuer_from_aia_total", metrics.NewInt(issuersFromAIA)).
+		AddMetric("cert_hostname_mismatch_total", metrics.NewInt(hostnameMismatches)).
+		AddMetric("chain_akid_skid_mismatch_total", metrics.NewInt(chainLinkageMismatches)).
+		AddMetric("cert_ip_inconsistency_total", metrics.NewInt(certIPInconsistencies)).
+		AddMetric("cert_download_breaker_open", metrics.NewInt(boolToInt(breakerOpen))).
+		AddMetric("cert_download_backoff_sec", metrics.NewFloat(backoff.Seconds())).
+		AddMetric("ipv4_used_total", metrics.NewInt(p.ipv4Used.Load())).
+		AddMetric("ipv6_used_total", metrics.NewInt(p.ipv6Used.Load())).
+		AddMetric("cert_update_worker_queue_depth", metrics.NewInt(p.certUpdateQueueDepth.Load())).
+		AddMetric("dns_cache_hit_total", metrics.NewInt(p.dialer.hits.Load())).
+		AddMetric("dns_cache_miss_total", metrics.NewInt(p.dialer.misses.Load())).
+		AddMetric("aia_cache_hit_total", metrics.NewInt(p.aiaCacheHits.Load())).
+		AddMetric("aia_cache_miss_total", metrics.NewInt(p.aiaCacheMisses.Load())).
+		AddMetric("aia_redirect_total", p.aiaRedirects.Clone()).
+		AddMetric("aia_redirect_loop_total", metrics.NewInt(p.aiaRedirectLoops.Load())).
+		AddLabel("ptype", "ocsp").
+		AddLabel("probe", p.name).
+		AddLabel("dst", target.Name)
+	if certDownloadErrors != nil {
+		em.AddMetric("cert_download_error_total", certDownloadErrors.Clone())
+	}
+	if chainLengthViolations != nil {
+		em.AddMetric("chain_length_violation_total", chainLengthViolations.Clone())
+	}
+	if selectedServer != "" {
+		em.AddLabel("server_selected", selectedServer)
+	}
+	for _, al := range p.opts.AdditionalLabels {
+		em.AddLabel(al.KeyValueForTarget(target))
+	}
+
+	if !ok || cert == nil {
+		return em
+	}
+
+	daysUntilExpiry := time.Until(cert.NotAfter).Hours() / 24
+
+	em.AddMetric("sct_count", metrics.NewInt(meta.sctCount())).
+		AddMetric("sct_tls_extension_count", metrics.NewInt(meta.sctTLSExtension)).
+		AddMetric("sct_embedded_count", metrics.NewInt(meta.sctEmbedded)).
+		AddMetric("cert_hostname_match", metrics.NewInt(boolToInt(meta.hostnameMatch))).
+		AddMetric("cert_san_count", metrics.NewInt(meta.sanCount)).
+		AddMetric("cert_wildcard_san_count", metrics.NewInt(meta.wildcardCount)).
+		AddMetric("cert_is_wildcard", metrics.NewInt(boolToInt(meta.wildcardCount > 0))).
+		AddMetric("cert_has_server_auth_eku", metrics.NewInt(boolToInt(meta.serverAuthEKU))).
+		AddMetric("cert_is_ev", metrics.NewInt(boolToInt(meta.validationType == "EV"))).
+		AddMetric("cert_must_staple", metrics.NewInt(boolToInt(meta.mustStaple))).
+		AddMetric("cert_chain_length", metrics.NewInt(meta.chainLength)).
+		AddMetric("cert_key_rotated_total", metrics.NewInt(keyRotations)).
+		AddMetric("cert_not_before_unix", metrics.NewInt(meta.notBefore.Unix())).
+		AddMetric("cert_validity_span_days", metrics.NewFloat(meta.validitySpanDays)).
+		AddMetric("cert_expiry_warning", metrics.NewInt(boolToInt(daysUntilExpiry < float64(p.c.GetCertExpiryWarningDays())))).
+		AddMetric("cert_expiry_critical", metrics.NewInt(boolToInt(daysUntilExpiry < float64(p.c.GetCertExpiryCriticalDays())))).
+		AddLabel("cert_validation_type", meta.validationType).
+		AddLabel("cert_ev_ca_name", meta.evCAName).
+		AddLabel("cert_org", meta.org).
+		AddLabel("cert_key_detail", meta.keyDetail).
+		AddLabel("cert_spki_hash", meta.spkiHash)
+	if !lastChanged.IsZero() {
+		em.AddMetric("cert_last_changed_unix", metrics.NewInt(lastChanged.Unix()))
+	}
+	em.AddMetric("tls_version_negotiated", metrics.NewString(meta.tlsVersion))
+	if p.c.GetMinTlsVersion() != "" {
+		em.AddMetric("tls_version_too_low_total", metrics.NewInt(tlsVersionTooLow))
+	}
+	if p.c.GetRequireServerAuthEku() {
+		em.AddMetric("invalid_eku_total", metrics.NewInt(invalidEKU))
+	}
+	if p.c.GetCheckStaple() || p.c.GetEnforceMustStaple() {
+		em.AddMetric("must_staple_violation_total", metrics.NewInt(mustStapleViolations))
+	}
+	if hasChainStatus {
+		chainStatusMap := metrics.NewMap("status")
+		chainStatusMap.IncKey(chainStatus.status)
+		em.AddMetric("chain_ocsp_status", chainStatusMap).
+			AddMetric("chain_ocsp_all_good", metrics.NewInt(boolToInt(chainStatus.status == "all_good"))).
+			AddMetric("first_non_good_depth", metrics.NewInt(int64(chainStatus.firstNonGood)))
+	}
+	if len(p.c.GetOcspFallbackUrls()) > 0 {
+		em.AddMetric("fallback_server_used_total", metrics.NewInt(fallbackUsed))
+	}
+	if p.c.GetPrimaryServerOnly() {
+		em.AddMetric("failover_event_total", metrics.NewInt(failovers)).
+			AddLabel("active_server", p.currentServerFor(target.Key()))
+	}
+	return em
+}
+
+// hasServerAuthEKU returns true if the target's certificate allows TLS server
+// authentication.
+func (p *Probe) hasServerAuthEKU(target endpoint.Endpoint) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	meta, ok := p.certMeta[target.Key()]
+	return ok && meta.serverAuthEKU
+}
+
+// mustStapleViolated returns true if the target's certificate requires OCSP
+// stapling, but the target didn't staple a response when it was downloaded.
+func (p *Probe) mustStapleViolated(target endpoint.Endpoint) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	meta, ok := p.certMeta[target.Key()]
+	return ok && meta.mustStaple && !meta.stapled
+}
+
+// certChangedMetrics returns an event describing the replacement of the
+// target's certificate.
+func (p *Probe) certChangedMetrics(ts time.Time, target endpoint.Endpoint, oldCert, newCert *x509.Certificate) *metrics.EventMetrics {
+	em := metrics.NewEventMetrics(ts).
+		AddMetric("cert_changed", metrics.NewInt(1)).
+		AddLabel("ptype", "ocsp").
+		AddLabel("probe", p.name).
+		AddLabel("dst", target.Name).
+		AddLabel("change_type", diffCerts(oldCert, newCert)).
+		AddLabel("old_serial", oldCert.SerialNumber.Text(16)).
+		AddLabel("new_serial", newCert.SerialNumber.Text(16))
+	em.Kind = metrics.GAUGE
+	for _, al := range p.opts.AdditionalLabels {
+		em.AddLabel(al.KeyValueForTarget(target))
+	}
+	return em
+}
+
+func (p *Probe) gapBetweenTargets() time.Duration {
+	interTargetGap := time.Duration(p.c.GetIntervalBetweenTargetsMsec()) * time.Millisecond
+
+	// If not configured by user, determine based on probe interval and number of
+	// targets.
+	if interTargetGap == 0 {
+		// Use 1/10th of the probe interval to spread out target groroutines.
+		interTargetGap = p.opts.Interval / time.Duration(10*len(p.targets))
+	}
+
+	return interTargetGap
+}
+
+// rateLimiter returns the rate limiter for the OCSP server, or nil if
+// requests to the server are not rate limited.
+func (p *Probe) rateLimiter(server string) *rate.Limiter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.serverRateLimiters[server]
+}
+
+// retryAfterPending returns true if the OCSP server asked not to be
+// requested until a Retry-After deadline which hasn't passed yet.
+func (p *Probe) retryAfterPending(server string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return time.Now().Before(p.serverRetryAfter[server])
+}
+
+// serverLock returns the lock serializing requests to the OCSP server.
+func (p *Probe) serverLock(server string) *sync.Mutex {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	mu, ok := p.serverMu[server]
+	if !ok {
+		mu = &sync.Mutex{}
+		p.serverMu[server] = mu
+	}
+	return mu
+}
+
+// requestMethod returns the HTTP method of OCSP requests, always GET in
+// rfc5019_mode.
+func (p *Probe) requestMethod() string {
+	if p.c.GetRfc5019Mode() {
+		return http.MethodGet
+	}
+	return p.c.GetRequestMethod()
+}
+
+// useNonce returns true if OCSP requests carry a nonce, never in
+// rfc5019_mode.
+func (p *Probe) useNonce() bool {
+	return p.c.GetUseNonce() && !p.c.GetRfc5019Mode()
+}
+
+// Create OCSP http requests, one per OSCP server specified in certificate
+func (p *Probe) ocspRequestForTarget(target endpoint.Endpoint) (map[string]*http.Request, error) {
+	var err error
+
+	cert, issuer := p.getCert(target.Key())
+	if cert == nil {
+		return nil, fmt.Errorf("no domain certificate for target %s", target.Key())
+	}
+
+	if len(cert.OCSPServer) < 1 {
+		return nil, fmt.Errorf("no OCSP servers defined for target %s", target.Key())
+	}
+
+	if issuer == nil {
+		return nil, fmt.Errorf("no issuer certificate for target %s", target.Key())
+	}
+
+	body, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		return nil, err
 	}
 
 	requests := make(map[string]*http.Request, len(cert.OCSPServer))
@@ -412,24 +1678,203 @@ func (p *Probe) ocspRequestForTarget(target endpoint.Endpoint) (map[string]*http
 			continue
 		}
 
-		requests[serverUrl.Host], err = http.NewRequest(http.MethodPost, cert.OCSPServer[i], bytes.NewBuffer(body))
+		requests[serverUrl.Host], err = p.newOCSPRequest(serverUrl, body)
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	servers := make([]string, 0, len(requests))
+	for server := range requests {
+		servers = append(servers, server)
+	}
+
+	p.mu.Lock()
+	fallback := p.needsFallback(target.Key(), servers)
+	if fallback {
+		p.fallbackUsed[target.Key()]++
+	}
+	p.mu.Unlock()
+
+	if fallback {
+		for _, server := range p.c.GetOcspFallbackUrls() {
+			serverUrl, err := url.Parse(server)
+			if err != nil {
+				p.l.Errorf("cannot parse URL for fallback OCSP server: %s", server)
+				continue
+			}
+			if _, ok := requests[serverUrl.Host]; ok {
+				continue
+			}
 
-		requests[serverUrl.Host].Header.Add("Content-Type", "application/ocsp-request")
-		requests[serverUrl.Host].Header.Add("Accept", "application/ocsp-response")
-		requests[serverUrl.Host].Header.Add("host", serverUrl.Host)
+			requests[serverUrl.Host], err = p.newOCSPRequest(serverUrl, body)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return requests, nil
 }
 
+// getFallbackRequest returns the OCSP POST request as a GET request,
+// regardless of its URL length.
+func getFallbackRequest(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return nil, errors.New("request body can't be read again")
+	}
+	rc, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	body, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	getURL, err := ocspGetURL(req.URL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	getReq, err := http.NewRequest(http.MethodGet, getURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	getReq.Header = req.Header.Clone()
+	getReq.Header.Del("Content-Type")
+	return getReq, nil
+}
+
+// ocspGetURL returns the URL of an OCSP GET request: the base64 encoded
+// request appended to the server URL, percent-encoding characters of the
+// standard base64 alphabet which aren't safe in URL paths.
+func ocspGetURL(serverURL string, requestBody []byte) (string, error) {
+	if _, err := url.Parse(serverURL); err != nil {
+		return "", err
+	}
+
+	encoded := ocspGetEscaper.Replace(base64.StdEncoding.EncodeToString(requestBody))
+	if !strings.HasSuffix(serverURL, "/") {
+		serverURL += "/"
+	}
+	return serverURL + encoded, nil
+}
+
+var ocspGetEscaper = strings.NewReplacer("+", "%2B", "/", "%2F", "=", "%3D")
+
+// targetContextKey is the request context key of the probed target.
+type targetContextKey struct{}
+
+// targetProxy returns a proxy function using the "http_proxy" label of the
+// request's target if set, or the fallback proxy function otherwise.
+func targetProxy(fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if target, ok := req.Context().Value(targetContextKey{}).(endpoint.Endpoint); ok {
+			if proxy := target.Labels["http_proxy"]; proxy != "" {
+				return url.Parse(proxy)
+			}
+		}
+		return fallback(req)
+	}
+}
+
+// roundTripperFunc adapts a function to the http.RoundTripper interface.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newOCSPRequest creates an OCSP request to the server, using the configured
+// HTTP method.
+func (p *Probe) newOCSPRequest(serverUrl *url.URL, body []byte) (*http.Request, error) {
+	getURL, err := ocspGetURL(serverUrl.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	var req *http.Request
+
+	if p.requestMethod() == http.MethodGet && len(getURL) <= maxGetRequestLen {
+		req, err = http.NewRequest(http.MethodGet, getURL, nil)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		req, err = http.NewRequest(http.MethodPost, serverUrl.String(), bytes.NewBuffer(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("Content-Type", "application/ocsp-request")
+	}
+
+	req.Header.Add("Accept", "application/ocsp-response")
+	req.Header.Add("host", serverUrl.Host)
+	if userAgent := p.c.GetHttpUserAgent(); userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	if p.c.GetEnableResponseDecompression() {
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
+
+	return req, nil
+}
+
 func ocspProbe(cli *http.Client, req *http.Request, issuer *x509.Certificate) (*callResult, error) {
+	call, output, err := fetchOCSPResponse(cli, req)
+	if err != nil {
+		return call, err
+	}
+
+	if err := checkResponseSize(output); err != nil {
+		return call, err
+	}
+
+	result, err := ocsp.ParseResponse(output, issuer)
+	if err != nil {
+		return call, err
+	}
+
+	call.setResponse(result)
+	call.responseSize = len(output)
+
+	return call, nil
+}
+
+// checkResponseSize returns an error if the OCSP response body is empty or
+// too small to be a valid response, which would otherwise be reported as an
+// opaque ASN.1 parse error.
+func checkResponseSize(output []byte) error {
+	switch {
+	case len(output) == 0:
+		return errEmptyResponseBody
+	case len(output) < minResponseSize:
+		return errors.Wrapf(errResponseTooSmall, "%d bytes", len(output))
+	}
+	return nil
+}
+
+// setResponse records the parsed OCSP response in the call result.
+func (c *callResult) setResponse(result *ocsp.Response) {
+	c.OCSPStatusCode = result.Status
+	c.response = result
+	c.responseAgeSec = time.Since(result.ThisUpdate).Seconds()
+	if !result.NextUpdate.IsZero() {
+		c.nextUpdateInSec = time.Until(result.NextUpdate).Seconds()
+	}
+}
+
+// fetchOCSPResponse sends the OCSP request and returns the raw response body.
+// The returned callResult is never nil.
+func fetchOCSPResponse(cli *http.Client, req *http.Request) (*callResult, []byte, error) {
 	var (
 		call = &callResult{
-			HTTPStatusCode: 0,
-			OCSPStatusCode: ocsp.ServerFailed,
+			HTTPStatusCode:     0,
+			OCSPStatusCode:     ocsp.ServerFailed,
+			cacheControlMaxAge: -1,
 		}
 		start = time.Now()
 	)
@@ -438,7 +1883,7 @@ func ocspProbe(cli *http.Client, req *http.Request, issuer *x509.Certificate) (*
 	call.spent = time.Since(start)
 
 	if err != nil {
-		return call, errors.Wrap(err, "http.Client.Do()")
+		return call, nil, errors.Wrap(err, "http.Client.Do()")
 	}
 
 	defer func() {
@@ -448,112 +1893,587 @@ func ocspProbe(cli *http.Client, req *http.Request, issuer *x509.Certificate) (*
 	}()
 
 	call.HTTPStatusCode = res.StatusCode
+	call.cacheControlMaxAge = cacheControlMaxAge(res.Header)
+	call.retryAfter = parseRetryAfter(res.Header.Get("Retry-After"))
 
 	if res.StatusCode != http.StatusOK {
-		return call, fmt.Errorf("something went wrong, returned status %d and message %q",
+		return call, nil, fmt.Errorf("something went wrong, returned status %d and message %q",
 			res.StatusCode,
 			res.Status)
 	}
 
-	output, err := io.ReadAll(res.Body)
-	if err != nil {
-		return call, err
+	body := res.Body
+	switch encoding := res.Header.Get("Content-Encoding"); encoding {
+	case "":
+	case "gzip":
+		if body, err = gzip.NewReader(res.Body); err != nil {
+			return call, nil, errors.Wrap(err, "gzip.NewReader()")
+		}
+		call.compressed = true
+	case "deflate":
+		if body, err = zlib.NewReader(res.Body); err != nil {
+			return call, nil, errors.Wrap(err, "zlib.NewReader()")
+		}
+		call.compressed = true
+	default:
+		return call, nil, fmt.Errorf("unsupported response Content-Encoding %q", encoding)
 	}
 
-	result, err := ocsp.ParseResponse(output, issuer)
+	output, err := io.ReadAll(body)
 	if err != nil {
-		return call, err
+		return call, nil, err
 	}
 
-	call.OCSPStatusCode = result.Status
+	return call, output, nil
+}
 
-	return call, nil
+// certUpdate is the result of a certificate update of a target.
+type certUpdate struct {
+	target endpoint.Endpoint
+	cert   *x509.Certificate
+	state  *tls.ConnectionState
+	issuer *x509.Certificate
 }
 
-func (p *Probe) updateCertificates() {
-	p.Lock()
-	defer p.Unlock()
+func (p *Probe) updateCertificates(dataChan chan *metrics.EventMetrics) {
+	// Certificate change events are sent after all targets are updated to
+	// not block on a busy data channel.
+	var events []*metrics.EventMetrics
+	defer func() {
+		if dataChan == nil {
+			return
+		}
+		for _, em := range events {
+			p.opts.LogMetrics(em)
+			dataChan <- em
+		}
+	}()
 
 	p.l.Debugf("Updating certificates")
 
-	for _, target := range p.opts.Targets.ListEndpoints() {
-		cert, err := p.downloadServerCertificate(target.Name)
+	targets := p.opts.Targets.ListEndpoints()
+	p.certUpdateQueueDepth.Store(int64(len(targets)))
+
+	// Certificates are downloaded by cert_update_workers workers without
+	// holding any locks, so that slow targets don't block probing of other
+	// targets, and stored here.
+	workCh := make(chan endpoint.Endpoint, len(targets))
+	resultCh := make(chan *certUpdate, len(targets))
+	for _, target := range targets {
+		workCh <- target
+	}
+	close(workCh)
+
+	var wg sync.WaitGroup
+	for range min(int(p.c.GetCertUpdateWorkers()), len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range workCh {
+				resultCh <- p.fetchCertificates(target)
+				p.certUpdateQueueDepth.Add(-1)
+			}
+		}()
+	}
+	wg.Wait()
+	close(resultCh)
+
+	for update := range resultCh {
+		if update == nil {
+			continue
+		}
+		if em := p.storeCertificates(update.target, update.cert, update.state, update.issuer); em != nil {
+			events = append(events, em)
+		}
+	}
+}
+
+// fetchCertificates downloads the target's certificate and resolves its
+// issuer, or issues them from Vault if configured. It returns nil if there's
+// no certificate to store.
+func (p *Probe) fetchCertificates(target endpoint.Endpoint) *certUpdate {
+	if p.c.GetVaultAddr() != "" {
+		// Issuing a new certificate on every update would flood the PKI
+		// mount and look like a certificate change every time.
+		if p.vaultCertValid(target) {
+			return nil
+		}
+		cert, issuer, err := p.issueVaultCertificate(target)
 		if err != nil {
-			p.l.Errorf("error downloading server certificate: %s", err.Error())
-			return
+			p.l.Errorf("error issuing Vault certificate for target %s: %s", target.Name, err.Error())
+			return nil
 		}
+		state := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert, issuer}}
+		return &certUpdate{target: target, cert: cert, state: state, issuer: issuer}
+	}
 
-		if cert == nil {
-			return
+	if !p.certDownloadAllowed(target.Key()) {
+		p.l.Debugf("Circuit breaker open for target %s, using the last downloaded certificate", target.Name)
+		return nil
+	}
+	if p.certDownloadBackedOff(target.Key()) {
+		p.l.Debugf("Backing off certificate download for target %s", target.Name)
+		return nil
+	}
+
+	var (
+		cert  *x509.Certificate
+		state *tls.ConnectionState
+		err   error
+	)
+	if p.s3Client != nil {
+		cert, state, err = p.downloadS3Certificate(target)
+	} else {
+		cert, state, err = p.downloadServerCertificate(target.Name, nil)
+	}
+	p.recordCertDownload(target.Key(), err == nil)
+	p.updateCertDownloadBackoff(target.Key(), err == nil)
+	if err != nil {
+		reason := classifyCertDownloadError(err)
+		p.l.Errorf("error downloading server certificate for target %s (%s): %s", target.Name, reason, err.Error())
+		p.mu.Lock()
+		if errors.Is(err, errTLSVersionTooLow) {
+			p.tlsVersionTooLow[target.Key()]++
+		}
+		if _, ok := p.certDownloadErrors[target.Key()]; !ok {
+			p.certDownloadErrors[target.Key()] = metrics.NewMap("reason")
 		}
+		p.certDownloadErrors[target.Key()].IncKey(reason)
+		p.mu.Unlock()
+		return nil
+	}
 
-		p.certs[target.Key()] = cert
+	if cert == nil {
+		return nil
+	}
 
-		var issuer *x509.Certificate
+	if p.c.GetProbeAllTargetIps() {
+		p.checkTargetIPs(target, cert)
+	}
+
+	// Most targets serve the issuer, only fetch it from the AIA URLs if
+	// they don't.
+	issuer := issuerFromChain(cert, state.PeerCertificates)
+	if issuer == nil {
 		for _, issuingCert := range cert.IssuingCertificateURL {
-			issuer, err = fetchRemote(issuingCert)
+			issuer, err = p.fetchRemote(issuingCert)
 			if err != nil {
 				continue
 			}
 			break
 		}
+		if issuer != nil {
+			p.mu.Lock()
+			p.issuersFromAIA[target.Key()]++
+			p.mu.Unlock()
+		}
+	} else {
+		p.mu.Lock()
+		p.issuersFromChain[target.Key()]++
+		p.mu.Unlock()
+	}
 
-		if issuer == nil {
-			p.l.Errorf("error downloading issuer certificate")
-			return
+	return &certUpdate{target: target, cert: cert, state: state, issuer: issuer}
+}
+
+// storeCertificates stores the downloaded certificate and its issuer for the
+// target. It returns the certificate change event, if the certificate has
+// changed.
+func (p *Probe) storeCertificates(target endpoint.Endpoint, cert *x509.Certificate, state *tls.ConnectionState, issuer *x509.Certificate) *metrics.EventMetrics {
+	oldCert, _ := p.getCert(target.Key())
+	p.setCert(target.Key(), cert, issuer)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var event *metrics.EventMetrics
+	if oldCert != nil && !bytes.Equal(oldCert.Raw, cert.Raw) {
+		p.l.Infof("Certificate changed for target %s: serial %s -> %s", target.Name, oldCert.SerialNumber.Text(16), cert.SerialNumber.Text(16))
+		now := time.Now()
+		p.certLastChanged[target.Key()] = now
+		event = p.certChangedMetrics(now, target, oldCert, cert)
+	}
+
+	meta := newCertMeta(cert, state, p.c.GetEvPolicyOids())
+	if err := cert.VerifyHostname(targetHostname(target.Name)); err != nil {
+		p.l.Warningf("Certificate of target %s doesn't match its hostname: %v", target.Name, err)
+		p.hostnameMismatches[target.Key()]++
+	} else {
+		meta.hostnameMatch = true
+	}
+
+	if (p.c.GetCheckStaple() || p.c.GetEnforceMustStaple()) && meta.mustStaple && !meta.stapled {
+		if p.c.GetEnforceMustStaple() {
+			p.l.Errorf("Certificate %s of target %s requires OCSP stapling, but the target didn't staple a response", cert.SerialNumber.Text(16), target.Name)
+		} else {
+			p.l.Warningf("Certificate of target %s requires OCSP stapling, but the target didn't staple a response", target.Name)
+		}
+		p.mustStapleViolations[target.Key()]++
+	}
+
+	var chainLengthViolation string
+	switch {
+	case meta.chainLength < int64(p.c.GetMinCertChainLength()):
+		chainLengthViolation = "too_short"
+	case p.c.GetMaxCertChainLength() > 0 && meta.chainLength > int64(p.c.GetMaxCertChainLength()):
+		chainLengthViolation = "too_long"
+	}
+	if chainLengthViolation != "" {
+		p.l.Warningf("Target %s serves a chain of %d certificates (%s)", target.Name, meta.chainLength, chainLengthViolation)
+		if _, ok := p.chainLengthViolations[target.Key()]; !ok {
+			p.chainLengthViolations[target.Key()] = metrics.NewMap("reason")
+		}
+		p.chainLengthViolations[target.Key()].IncKey(chainLengthViolation)
+	}
+
+	if oldMeta, ok := p.certMeta[target.Key()]; ok && oldMeta.spkiHash != meta.spkiHash {
+		p.l.Infof("Key of target %s rotated", target.Name)
+		p.keyRotations[target.Key()]++
+	}
+
+	p.certMeta[target.Key()] = meta
+	p.updateServerState(target.Key(), cert.OCSPServer)
+	if err := validateChainLinkage(state.PeerCertificates); err != nil {
+		// Don't probe the intermediates with the wrong issuers.
+		p.l.Warningf("Certificate chain of target %s is misordered or mixed: %v", target.Name, err)
+		p.chainLinkageMismatches[target.Key()]++
+		p.chains[target.Key()] = state.PeerCertificates[:1]
+	} else {
+		p.chains[target.Key()] = state.PeerCertificates
+	}
+
+	if issuer == nil {
+		p.l.Errorf("error downloading issuer certificate for target %s", target.Name)
+		p.issuerFetchFailures[target.Key()]++
+		return event
+	}
+
+	if limit := p.c.GetMaxRequestsPerServerPerSec(); limit > 0 {
+		for _, server := range cert.OCSPServer {
+			serverUrl, err := url.Parse(server)
+			if err != nil {
+				continue
+			}
+			if _, ok := p.serverRateLimiters[serverUrl.Host]; !ok {
+				p.serverRateLimiters[serverUrl.Host] = rate.NewLimiter(rate.Limit(limit), 1)
+			}
 		}
+	}
+
+	return event
+}
+
+// targetHostname returns the target name without the port.
+func targetHostname(name string) string {
+	if host, _, err := net.SplitHostPort(name); err == nil {
+		return host
+	}
+	return strings.Trim(name, "[]")
+}
 
-		p.issuers[target.Key()] = issuer
+// getCert returns the target's certificate and its issuer, nil if not
+// downloaded yet.
+func (p *Probe) getCert(key string) (cert, issuer *x509.Certificate) {
+	p.certLocksMu.RLock()
+	entry, ok := p.certs[key]
+	p.certLocksMu.RUnlock()
+	if !ok {
+		return nil, nil
 	}
 
+	entry.RLock()
+	defer entry.RUnlock()
+
+	return entry.cert, entry.issuer
 }
 
-func (p *Probe) downloadServerCertificate(server string) (*x509.Certificate, error) {
+// setCert replaces the target's certificate and its issuer. The issuer is
+// kept if the new one is nil.
+func (p *Probe) setCert(key string, cert, issuer *x509.Certificate) {
+	p.certLocksMu.Lock()
+	entry, ok := p.certs[key]
+	if !ok {
+		entry = &targetCerts{}
+		p.certs[key] = entry
+	}
+	p.certLocksMu.Unlock()
+
+	entry.Lock()
+	defer entry.Unlock()
+
+	entry.cert = cert
+	if issuer != nil {
+		entry.issuer = issuer
+	}
+}
+
+// certKeys returns the keys of all targets with a certificate.
+func (p *Probe) certKeys() []string {
+	p.certLocksMu.RLock()
+	defer p.certLocksMu.RUnlock()
+
+	keys := make([]string, 0, len(p.certs))
+	for key := range p.certs {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// downloadServerCertificate connects to the server and returns its leaf
+// certificate along with the TLS connection state. If ip is set, it connects
+// to that address instead of resolving the server.
+func (p *Probe) downloadServerCertificate(server string, ip net.IP) (*x509.Certificate, *tls.ConnectionState, error) {
 
 	d := &net.Dialer{
-		Timeout: p.opts.Timeout,
+		Timeout:  p.opts.Timeout,
+		Resolver: p.resolver,
 	}
 
-	if strings.LastIndex(server, ":") == -1 {
-		server += ":" + defaultPort
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), defaultPort)
 	}
 
-	conn, err := tls.DialWithDialer(d, "tcp", server, &tls.Config{
+	host, port, _ := net.SplitHostPort(server)
+	config := &tls.Config{
 		InsecureSkipVerify: true,
-	})
+		ServerName:         host,
+	}
+	if p.c.GetVerifyTargetCertificate() {
+		config.InsecureSkipVerify = false
+		config.RootCAs = p.rootCAPool
+	}
+	if ip != nil {
+		server = net.JoinHostPort(ip.String(), port)
+	}
+
+	// Accept any version in the handshake to report the version negotiated
+	// by the target, min_tls_version is enforced below.
+	minVersion, checkVersion := tlsVersions[p.c.GetMinTlsVersion()]
+	if checkVersion {
+		config.MinVersion = tls.VersionTLS10
+	}
+
+	var (
+		conn *tls.Conn
+		err  error
+	)
+	if p.DialTLSHook != nil {
+		conn, err = p.DialTLSHook(p.network, server, config)
+	} else {
+		conn, err = tls.DialWithDialer(d, p.network, server, config)
+	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer func() { _ = conn.Close() }()
 
-	certs := conn.ConnectionState().PeerCertificates
-	if len(certs) < 0 {
-		return nil, fmt.Errorf("empty peer certificates: %s", server)
+	p.countIPVersion(conn)
+
+	state := conn.ConnectionState()
+	if checkVersion && state.Version < minVersion {
+		return nil, nil, errors.Wrapf(errTLSVersionTooLow, "%s negotiated %s", server, tlsVersionName(state.Version))
+	}
+	certs := state.PeerCertificates
+	if len(certs) == 0 {
+		return nil, nil, errors.Wrap(errEmptyChain, server)
+	}
+
+	return certs[0], &state, nil
+}
+
+// classifyCertDownloadError returns the reason of the certificate download
+// failure, used as the label of cert_download_error_total.
+func classifyCertDownloadError(err error) string {
+	var (
+		dnsErr   *net.DNSError
+		alertErr tls.AlertError
+		netErr   net.Error
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		return "dns_error"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection_refused"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "connection_timeout"
+	case errors.As(err, &alertErr):
+		return "tls_handshake_error"
+	case errors.Is(err, errEmptyChain):
+		return "empty_chain"
+	case errors.Is(err, errTLSVersionTooLow):
+		return "tls_version_too_low"
+	case strings.Contains(err.Error(), "failed to parse certificate"):
+		return "cert_parse_error"
+	}
+	return "other"
+}
+
+// countIPVersion counts the connection by the IP version of its remote
+// address.
+func (p *Probe) countIPVersion(conn net.Conn) {
+	addr, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return
+	}
+	if addr.IP.To4() != nil {
+		p.ipv4Used.Add(1)
+	} else {
+		p.ipv6Used.Add(1)
+	}
+}
+
+// loadCertPoolFromDir returns a pool of the certificates in all .pem and
+// .crt files of the directory.
+func loadCertPoolFromDir(dir string) (*x509.CertPool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
 	}
 
-	return certs[0], nil
+	pool := x509.NewCertPool()
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".pem" && ext != ".crt") {
+			continue
+		}
+
+		file := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates parsed from %s", file)
+		}
+	}
+	return pool, nil
 }
 
-func fetchRemote(url string) (*x509.Certificate, error) {
-	resp, err := http.Get(url)
+// checkAIARedirect is the redirect policy of AIA fetches. It stops after
+// max_aia_redirects redirects, and before following a redirect to an already
+// fetched URL, returning the redirect response.
+func (p *Probe) checkAIARedirect(req *http.Request, via []*http.Request) error {
+	p.aiaRedirects.IncKey(via[0].URL.String())
+
+	for _, prev := range via {
+		if prev.URL.String() == req.URL.String() {
+			p.l.Warningf("AIA fetch of %s redirected in a loop to %s", via[0].URL, req.URL)
+			p.aiaRedirectLoops.Add(1)
+			return http.ErrUseLastResponse
+		}
+	}
+
+	if len(via) > int(p.c.GetMaxAiaRedirects()) {
+		return fmt.Errorf("stopped after %d redirects", p.c.GetMaxAiaRedirects())
+	}
+	return nil
+}
+
+// fetchRemote downloads the certificate from the AIA URL. The certificate is
+// only downloaded again if the server reports it as modified.
+func (p *Probe) fetchRemote(url string) (*x509.Certificate, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	in, err := io.ReadAll(resp.Body)
+	p.mu.Lock()
+	cached, ok := p.aiaCache[url]
+	p.mu.Unlock()
+	if ok {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := p.aiaClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	p, _ := pem.Decode(in)
-	if p != nil {
+	if ok && resp.StatusCode == http.StatusNotModified {
+		p.aiaCacheHits.Add(1)
+		return cached.cert, nil
+	}
+	p.aiaCacheMisses.Add(1)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("AIA fetch of %s returned status %d", url, resp.StatusCode)
+	}
+
+	in, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := parseCertificate(in)
+	if err != nil {
+		return nil, err
+	}
+
+	if etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"); etag != "" || lastModified != "" {
+		p.mu.Lock()
+		p.aiaCache[url] = aiaCacheEntry{cert: cert, etag: etag, lastModified: lastModified}
+		p.mu.Unlock()
+	}
+
+	return cert, nil
+}
+
+// aiaCacheEntry is a certificate fetched from an AIA URL along with the
+// response's cache validators.
+type aiaCacheEntry struct {
+	cert         *x509.Certificate
+	etag         string
+	lastModified string
+}
+
+// parseCertificate parses a PEM or DER encoded certificate.
+func parseCertificate(in []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(in)
+	if block != nil {
 		return helpers.ParseCertificatePEM(in)
 	}
 
 	return x509.ParseCertificate(in)
 }
 
+// parseRetryAfter returns the deadline of the Retry-After header value in
+// either delta-seconds or HTTP-date format, or zero time if it's invalid.
+func parseRetryAfter(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if sec, err := strconv.Atoi(value); err == nil {
+		return time.Now().Add(time.Duration(sec) * time.Second)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// cacheControlMaxAge returns the max-age directive of the Cache-Control
+// header, or -1 if there is none.
+func cacheControlMaxAge(header http.Header) int64 {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(directive), "max-age=")
+		if !ok {
+			continue
+		}
+		maxAge, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return -1
+		}
+		return maxAge
+	}
+	return -1
+}
+
 // Return true if the underlying error indicates a http.Client timeout.
 //
 // Use for errors returned from http.Client methods (Get, Post).
@@ -567,6 +2487,43 @@ func isClientTimeout(err error) bool {
 	return false
 }
 
+// isDNSError returns true if the error was caused by a failed DNS lookup.
+func isDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// ocspStatusString returns a human-readable name of the OCSP status.
+func ocspStatusString(status int) string {
+	switch status {
+	case ocsp.Good:
+		return "good"
+	case ocsp.Revoked:
+		return "revoked"
+	case ocsp.Unknown:
+		return "unknown"
+	case ocsp.ServerFailed:
+		return "server_failed"
+	}
+	return "status_" + strconv.Itoa(status)
+}
+
+// countOCSPStatus counts the OCSP status by its code and, if enabled, by
+// its name.
+func (r *probeResult) countOCSPStatus(status int) {
+	r.ocspCodes.IncKey(strconv.Itoa(status))
+	if r.ocspStatusLabels != nil {
+		r.ocspStatusLabels.IncKey(ocspStatusString(status))
+	}
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 func ctxDone(ctx context.Context) bool {
 	select {
 	case <-ctx.Done():
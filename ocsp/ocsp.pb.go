@@ -26,6 +26,190 @@ type ProbeConf struct {
 	CertificateRefreshInterval *int32 `protobuf:"varint,1,opt,name=certificate_refresh_interval,json=certificateRefreshInterval,def=60000" json:"certificate_refresh_interval,omitempty"`
 	// Proxy URL, e.g. http://myproxy:3128
 	ProxyUrl *string `protobuf:"bytes,2,opt,name=proxy_url,json=proxyUrl" json:"proxy_url,omitempty"`
+	// Maximum age of the OCSP response (time since its thisUpdate), in seconds.
+	// Older responses are counted as stale. 0 disables the check.
+	MaxThisUpdateAgeSec *int32 `protobuf:"varint,3,opt,name=max_this_update_age_sec,json=maxThisUpdateAgeSec,def=0" json:"max_this_update_age_sec,omitempty"`
+	// If set, stale responses are counted as failed probes.
+	FailOnStaleResponse *bool `protobuf:"varint,4,opt,name=fail_on_stale_response,json=failOnStaleResponse,def=0" json:"fail_on_stale_response,omitempty"`
+	// Maximum number of requests per second sent to a single OCSP server,
+	// shared by all targets using that server. 0 disables rate limiting.
+	MaxRequestsPerServerPerSec *float64 `protobuf:"fixed64,5,opt,name=max_requests_per_server_per_sec,json=maxRequestsPerServerPerSec,def=0" json:"max_requests_per_server_per_sec,omitempty"`
+	// Client certificate and key (PEM) used for mutual TLS with OCSP servers.
+	// Both must be set to enable mTLS. The client certificate is never used
+	// when downloading target certificates.
+	OcspClientCertFile *string `protobuf:"bytes,6,opt,name=ocsp_client_cert_file,json=ocspClientCertFile" json:"ocsp_client_cert_file,omitempty"`
+	OcspClientKeyFile  *string `protobuf:"bytes,7,opt,name=ocsp_client_key_file,json=ocspClientKeyFile" json:"ocsp_client_key_file,omitempty"`
+	// Run a single probe cycle for all targets, print results as JSON to stdout
+	// and exit. Exit code is 0 only if all OCSP servers returned "good".
+	DryRun *bool `protobuf:"varint,8,opt,name=dry_run,json=dryRun,def=0" json:"dry_run,omitempty"`
+	// Combine OCSP requests of all targets sharing the same issuer and OCSP
+	// server into a single request with multiple certificates (RFC 5019,
+	// section 2.1.1). At most one batch request per issuer and server is sent
+	// per probe interval.
+	BatchOcspRequests *bool `protobuf:"varint,9,opt,name=batch_ocsp_requests,json=batchOcspRequests,def=0" json:"batch_ocsp_requests,omitempty"`
+	// Probe only one OCSP server of the certificate per probe cycle, switching
+	// to the next one after failover_threshold consecutive failures.
+	PrimaryServerOnly *bool  `protobuf:"varint,10,opt,name=primary_server_only,json=primaryServerOnly,def=0" json:"primary_server_only,omitempty"`
+	FailoverThreshold *int32 `protobuf:"varint,11,opt,name=failover_threshold,json=failoverThreshold,def=3" json:"failover_threshold,omitempty"`
+	// Also check the OCSP status of intermediate certificates served by the
+	// target, using the next certificate in the chain as the issuer.
+	CheckChainOcsp *bool `protobuf:"varint,12,opt,name=check_chain_ocsp,json=checkChainOcsp,def=0" json:"check_chain_ocsp,omitempty"`
+	// Directory to persist OCSP responses in, so that they are available right
+	// after the probe restarts. Responses past their nextUpdate are not loaded.
+	CacheDir *string `protobuf:"bytes,13,opt,name=cache_dir,json=cacheDir" json:"cache_dir,omitempty"`
+	// IP version used to connect to targets and OCSP servers: "ipv4", "ipv6"
+	// or "any".
+	IpVersion *string `protobuf:"bytes,14,opt,name=ip_version,json=ipVersion,def=any" json:"ip_version,omitempty"`
+	// Force HTTP/2 for OCSP requests, so that requests to the same OCSP server
+	// share a single connection.
+	UseHttp2 *bool `protobuf:"varint,15,opt,name=use_http2,json=useHttp2" json:"use_http2,omitempty"`
+	// If false, concurrent requests of all targets to the same OCSP server are
+	// serialized.
+	ParallelOcspServers *bool `protobuf:"varint,16,opt,name=parallel_ocsp_servers,json=parallelOcspServers,def=1" json:"parallel_ocsp_servers,omitempty"`
+	// Skip OCSP probing of targets whose certificate lacks the serverAuth
+	// extended key usage.
+	RequireServerAuthEku *bool `protobuf:"varint,17,opt,name=require_server_auth_eku,json=requireServerAuthEku" json:"require_server_auth_eku,omitempty"`
+	// How long resolved addresses of OCSP servers are cached. Set to 0 to
+	// disable caching.
+	DnsCacheTtlSec *int32 `protobuf:"varint,18,opt,name=dns_cache_ttl_sec,json=dnsCacheTtlSec,def=300" json:"dns_cache_ttl_sec,omitempty"`
+	// Additional certificate policy OIDs classifying certificates as extended
+	// validation, e.g. "1.3.6.1.4.1.34697.2.1".
+	EvPolicyOids []string `protobuf:"bytes,19,rep,name=ev_policy_oids,json=evPolicyOids" json:"ev_policy_oids,omitempty"`
+	// Count responses whose thisUpdate is older than this, in seconds. Zero
+	// disables the check.
+	MaxResponseAgeSec *int32 `protobuf:"varint,20,opt,name=max_response_age_sec,json=maxResponseAgeSec,def=0" json:"max_response_age_sec,omitempty"`
+	// Count responses whose nextUpdate is less than this many seconds away.
+	// Zero disables the check.
+	MinNextUpdateSec *int32 `protobuf:"varint,21,opt,name=min_next_update_sec,json=minNextUpdateSec,def=0" json:"min_next_update_sec,omitempty"`
+	// Count responses whose thisUpdate is more than this many seconds in the
+	// future, indicating clock skew of the probe host or the OCSP responder.
+	MaxClockSkewSec *int32 `protobuf:"varint,22,opt,name=max_clock_skew_sec,json=maxClockSkewSec,def=300" json:"max_clock_skew_sec,omitempty"`
+	// Number of days before certificate expiry to raise the cert_expiry_warning
+	// and cert_expiry_critical metrics.
+	CertExpiryWarningDays  *int32 `protobuf:"varint,23,opt,name=cert_expiry_warning_days,json=certExpiryWarningDays,def=30" json:"cert_expiry_warning_days,omitempty"`
+	CertExpiryCriticalDays *int32 `protobuf:"varint,24,opt,name=cert_expiry_critical_days,json=certExpiryCriticalDays,def=7" json:"cert_expiry_critical_days,omitempty"`
+	// URL to POST a JSON notification to when a certificate is found revoked.
+	RevocationWebhookUrl *string `protobuf:"bytes,25,opt,name=revocation_webhook_url,json=revocationWebhookUrl" json:"revocation_webhook_url,omitempty"`
+	// Timeout of revocation webhook requests.
+	WebhookTimeoutSec *int32 `protobuf:"varint,26,opt,name=webhook_timeout_sec,json=webhookTimeoutSec,def=10" json:"webhook_timeout_sec,omitempty"`
+	// Minimum interval between notifications about the same revoked
+	// certificate from the same OCSP server.
+	RevocationAlertCooldownSec *int32 `protobuf:"varint,27,opt,name=revocation_alert_cooldown_sec,json=revocationAlertCooldownSec,def=3600" json:"revocation_alert_cooldown_sec,omitempty"`
+	// User-Agent header of OCSP requests. Go's default is used if empty.
+	// Setting it to e.g.
+	// "cloudprober-ocsp/1.0 (+https://github.com/drivenet/cloudprober-ocsp)"
+	// helps CAs identify probe traffic in their OCSP access logs.
+	HttpUserAgent *string `protobuf:"bytes,28,opt,name=http_user_agent,json=httpUserAgent" json:"http_user_agent,omitempty"`
+	// Minimum TLS version of targets, "TLS12" or "TLS13". Certificates are not
+	// downloaded from targets negotiating a lower version.
+	MinTlsVersion *string `protobuf:"bytes,29,opt,name=min_tls_version,json=minTlsVersion" json:"min_tls_version,omitempty"`
+	// Directory with root CA certificates in .pem and .crt files, used to
+	// verify OCSP servers instead of the system roots.
+	RootCaCertDir *string `protobuf:"bytes,30,opt,name=root_ca_cert_dir,json=rootCaCertDir" json:"root_ca_cert_dir,omitempty"`
+	// Verify target certificates against the root_ca_cert_dir roots when
+	// downloading them.
+	VerifyTargetCertificate *bool `protobuf:"varint,31,opt,name=verify_target_certificate,json=verifyTargetCertificate" json:"verify_target_certificate,omitempty"`
+	// Accept gzip and deflate compressed OCSP responses.
+	EnableResponseDecompression *bool `protobuf:"varint,32,opt,name=enable_response_decompression,json=enableResponseDecompression" json:"enable_response_decompression,omitempty"`
+	// HTTP method of OCSP requests, "POST" or "GET". GET requests longer than
+	// 255 bytes are sent with POST instead (RFC 6960, appendix A.1).
+	RequestMethod *string `protobuf:"bytes,33,opt,name=request_method,json=requestMethod,def=POST" json:"request_method,omitempty"`
+	// OCSP server URLs to also probe when all OCSP servers of the certificate
+	// have failed fallback_threshold times in a row.
+	OcspFallbackUrls  []string `protobuf:"bytes,34,rep,name=ocsp_fallback_urls,json=ocspFallbackUrls" json:"ocsp_fallback_urls,omitempty"`
+	FallbackThreshold *int32   `protobuf:"varint,35,opt,name=fallback_threshold,json=fallbackThreshold,def=3" json:"fallback_threshold,omitempty"`
+	// Stop downloading a target's certificate for
+	// cert_download_breaker_cooldown_sec after this many consecutive failures,
+	// using the last downloaded certificate while it's valid.
+	CertDownloadBreakerThreshold   *int32 `protobuf:"varint,36,opt,name=cert_download_breaker_threshold,json=certDownloadBreakerThreshold,def=5" json:"cert_download_breaker_threshold,omitempty"`
+	CertDownloadBreakerCooldownSec *int32 `protobuf:"varint,37,opt,name=cert_download_breaker_cooldown_sec,json=certDownloadBreakerCooldownSec,def=300" json:"cert_download_breaker_cooldown_sec,omitempty"`
+	// Maximum delay between certificate download attempts of a failing
+	// target. The delay doubles with every consecutive failure.
+	MaxCertDownloadBackoffSec *int32 `protobuf:"varint,38,opt,name=max_cert_download_backoff_sec,json=maxCertDownloadBackoffSec,def=3600" json:"max_cert_download_backoff_sec,omitempty"`
+	// Number of last probe results of each OCSP server used to compute
+	// ocsp_rolling_success_rate.
+	SuccessWindowSize *int32 `protobuf:"varint,39,opt,name=success_window_size,json=successWindowSize,def=10" json:"success_window_size,omitempty"`
+	// Format of per-request probe result logs, "text" or "json". With "json",
+	// each OCSP call result is also logged as a JSON line.
+	LogFormat *string `protobuf:"bytes,40,opt,name=log_format,json=logFormat,def=text" json:"log_format,omitempty"`
+	// Add a random nonce to OCSP requests (RFC 8954) and check that responses
+	// echo it. Ignored for batch_ocsp_requests.
+	UseNonce *bool `protobuf:"varint,41,opt,name=use_nonce,json=useNonce" json:"use_nonce,omitempty"`
+	// With use_nonce, also treat responses without a nonce as failures. RFC
+	// 5019 allows responders to omit it.
+	RequireNonceEcho *bool `protobuf:"varint,42,opt,name=require_nonce_echo,json=requireNonceEcho" json:"require_nonce_echo,omitempty"`
+	// DNS server ("host:port", port 53 if omitted) resolving hosts when
+	// downloading target and AIA certificates, instead of the system resolver.
+	DnsServerAddr *string `protobuf:"bytes,43,opt,name=dns_server_addr,json=dnsServerAddr" json:"dns_server_addr,omitempty"`
+	// Maximum delay before probing an OCSP server again after tryLater
+	// responses. The delay starts at the probe interval and doubles with every
+	// consecutive tryLater response.
+	TryLaterMaxBackoffSec *int32 `protobuf:"varint,44,opt,name=try_later_max_backoff_sec,json=tryLaterMaxBackoffSec,def=600" json:"try_later_max_backoff_sec,omitempty"`
+	// Vault address, PKI secrets engine mount and role. If set, certificates
+	// for the targets' hostnames are issued by Vault instead of downloaded from
+	// the targets, to test the OCSP responder of a Vault CA in isolation. The
+	// Vault token is read from the VAULT_TOKEN environment variable.
+	VaultAddr     *string `protobuf:"bytes,45,opt,name=vault_addr,json=vaultAddr" json:"vault_addr,omitempty"`
+	VaultPkiMount *string `protobuf:"bytes,46,opt,name=vault_pki_mount,json=vaultPkiMount,def=pki" json:"vault_pki_mount,omitempty"`
+	VaultPkiRole  *string `protobuf:"bytes,47,opt,name=vault_pki_role,json=vaultPkiRole" json:"vault_pki_role,omitempty"`
+	// Retry POST requests rejected with 405 Method Not Allowed as GET requests.
+	FallbackToGet *bool `protobuf:"varint,48,opt,name=fallback_to_get,json=fallbackToGet" json:"fallback_to_get,omitempty"`
+	// Number of targets whose certificates are downloaded concurrently.
+	CertUpdateWorkers *int32 `protobuf:"varint,49,opt,name=cert_update_workers,json=certUpdateWorkers,def=5" json:"cert_update_workers,omitempty"`
+	// Number of last probe results of each OCSP server used to compute
+	// ocsp_availability_percent.
+	AvailabilityWindowSize *int32 `protobuf:"varint,50,opt,name=availability_window_size,json=availabilityWindowSize,def=100" json:"availability_window_size,omitempty"`
+	// Log the certificate serial number and issuer key hash of every OCSP
+	// request with its result, to correlate probes with OCSP server logs.
+	StructuredRequestLogging *bool `protobuf:"varint,51,opt,name=structured_request_logging,json=structuredRequestLogging" json:"structured_request_logging,omitempty"`
+	// Count downloads of must-staple certificates (RFC 7633) without a stapled
+	// OCSP response as must_staple_violation_total.
+	CheckStaple *bool `protobuf:"varint,52,opt,name=check_staple,json=checkStaple" json:"check_staple,omitempty"`
+	// Bounds of the number of certificates served by targets, counted as
+	// chain_length_violation_total. 0 disables the bound.
+	MinCertChainLength *int32 `protobuf:"varint,53,opt,name=min_cert_chain_length,json=minCertChainLength,def=0" json:"min_cert_chain_length,omitempty"`
+	MaxCertChainLength *int32 `protobuf:"varint,54,opt,name=max_cert_chain_length,json=maxCertChainLength,def=5" json:"max_cert_chain_length,omitempty"`
+	// Send conditional OCSP GET requests using the ETag and Last-Modified of
+	// the previous response, reusing it on 304 Not Modified. Requires
+	// request_method "GET" or rfc5019_mode, can't be used with use_nonce
+	// outside rfc5019_mode.
+	UseHttpCaching *bool `protobuf:"varint,55,opt,name=use_http_caching,json=useHttpCaching" json:"use_http_caching,omitempty"`
+	// Minimum time between ocsp_status_changed events of a target's OCSP
+	// server for the same new status. Reset when the status returns to good.
+	StatusChangeCooldownSec *int32 `protobuf:"varint,56,opt,name=status_change_cooldown_sec,json=statusChangeCooldownSec,def=3600" json:"status_change_cooldown_sec,omitempty"`
+	// Like check_staple, but also fail targets serving a must-staple
+	// certificate without a stapled OCSP response: they aren't probed until
+	// they staple one.
+	EnforceMustStaple *bool `protobuf:"varint,57,opt,name=enforce_must_staple,json=enforceMustStaple" json:"enforce_must_staple,omitempty"`
+	// Smoothing factor of the OCSP server latency moving average, in (0, 1].
+	// With parallel_ocsp_servers disabled, the server with the lowest average
+	// is probed first.
+	EwmaAlpha *float64 `protobuf:"fixed64,58,opt,name=ewma_alpha,json=ewmaAlpha,def=0.1" json:"ewma_alpha,omitempty"`
+	// Also download target certificates from each IP address of the target
+	// and count updates where they differ as cert_ip_inconsistency_total,
+	// e.g. CDN edges serving different certificates.
+	ProbeAllTargetIps *bool `protobuf:"varint,59,opt,name=probe_all_target_ips,json=probeAllTargetIps" json:"probe_all_target_ips,omitempty"`
+	// Also export OCSP statuses as ocsp-status, keyed by "good", "revoked",
+	// "unknown" or "server_failed" instead of the status codes of ocsp-code.
+	LabelOcspStatusAsString *bool `protobuf:"varint,60,opt,name=label_ocsp_status_as_string,json=labelOcspStatusAsString" json:"label_ocsp_status_as_string,omitempty"`
+	// Probe OCSP servers with the RFC 5019 lightweight profile: requests are
+	// sent with GET and without a nonce, use_nonce and request_method are
+	// ignored. Responses without nextUpdate or larger than 4096 bytes are
+	// counted as rfc5019_violation_total.
+	Rfc5019Mode *bool `protobuf:"varint,61,opt,name=rfc5019_mode,json=rfc5019Mode" json:"rfc5019_mode,omitempty"`
+	// Maximum number of redirects followed when fetching certificates from
+	// AIA URLs. Redirect loops are never followed.
+	MaxAiaRedirects *int32 `protobuf:"varint,62,opt,name=max_aia_redirects,json=maxAiaRedirects,def=3" json:"max_aia_redirects,omitempty"`
+	// S3 bucket and object key of the targets' PEM certificate chains, leaf
+	// first. If set, certificates are downloaded from S3 instead of the
+	// targets. "@target@" in the key is replaced by the target name. AWS
+	// credentials are read from the standard credential chain.
+	CertSourceS3Bucket *string `protobuf:"bytes,63,opt,name=cert_source_s3_bucket,json=certSourceS3Bucket" json:"cert_source_s3_bucket,omitempty"`
+	CertSourceS3Key    *string `protobuf:"bytes,64,opt,name=cert_source_s3_key,json=certSourceS3Key" json:"cert_source_s3_key,omitempty"`
+	// AWS region of the bucket, the region of the environment if not set.
+	CertSourceS3Region *string `protobuf:"bytes,65,opt,name=cert_source_s3_region,json=certSourceS3Region" json:"cert_source_s3_region,omitempty"`
+	// Endpoint URL of S3-compatible object storage, e.g. MinIO or Ceph.
+	CertSourceS3Endpoint *string `protobuf:"bytes,66,opt,name=cert_source_s3_endpoint,json=certSourceS3Endpoint" json:"cert_source_s3_endpoint,omitempty"`
 	// Interval between targets.
 	IntervalBetweenTargetsMsec *int32 `protobuf:"varint,98,opt,name=interval_between_targets_msec,json=intervalBetweenTargetsMsec,def=10" json:"interval_between_targets_msec,omitempty"`
 	// Requests per probe.
@@ -64,6 +248,40 @@ func (m *ProbeConf) XXX_DiscardUnknown() {
 var xxx_messageInfo_ProbeConf proto.InternalMessageInfo
 
 const Default_ProbeConf_CertificateRefreshInterval int32 = 60000
+const Default_ProbeConf_MaxThisUpdateAgeSec int32 = 0
+const Default_ProbeConf_FailOnStaleResponse bool = false
+const Default_ProbeConf_MaxRequestsPerServerPerSec float64 = 0
+const Default_ProbeConf_DryRun bool = false
+const Default_ProbeConf_BatchOcspRequests bool = false
+const Default_ProbeConf_PrimaryServerOnly bool = false
+const Default_ProbeConf_FailoverThreshold int32 = 3
+const Default_ProbeConf_CheckChainOcsp bool = false
+const Default_ProbeConf_IpVersion string = "any"
+const Default_ProbeConf_ParallelOcspServers bool = true
+const Default_ProbeConf_DnsCacheTtlSec int32 = 300
+const Default_ProbeConf_MaxResponseAgeSec int32 = 0
+const Default_ProbeConf_MinNextUpdateSec int32 = 0
+const Default_ProbeConf_MaxClockSkewSec int32 = 300
+const Default_ProbeConf_CertExpiryWarningDays int32 = 30
+const Default_ProbeConf_CertExpiryCriticalDays int32 = 7
+const Default_ProbeConf_WebhookTimeoutSec int32 = 10
+const Default_ProbeConf_RevocationAlertCooldownSec int32 = 3600
+const Default_ProbeConf_RequestMethod string = "POST"
+const Default_ProbeConf_FallbackThreshold int32 = 3
+const Default_ProbeConf_CertDownloadBreakerThreshold int32 = 5
+const Default_ProbeConf_CertDownloadBreakerCooldownSec int32 = 300
+const Default_ProbeConf_MaxCertDownloadBackoffSec int32 = 3600
+const Default_ProbeConf_SuccessWindowSize int32 = 10
+const Default_ProbeConf_LogFormat string = "text"
+const Default_ProbeConf_TryLaterMaxBackoffSec int32 = 600
+const Default_ProbeConf_VaultPkiMount string = "pki"
+const Default_ProbeConf_CertUpdateWorkers int32 = 5
+const Default_ProbeConf_AvailabilityWindowSize int32 = 100
+const Default_ProbeConf_MinCertChainLength int32 = 0
+const Default_ProbeConf_MaxCertChainLength int32 = 5
+const Default_ProbeConf_StatusChangeCooldownSec int32 = 3600
+const Default_ProbeConf_EwmaAlpha float64 = 0.1
+const Default_ProbeConf_MaxAiaRedirects int32 = 3
 const Default_ProbeConf_IntervalBetweenTargetsMsec int32 = 10
 const Default_ProbeConf_RequestsPerProbe int32 = 1
 
@@ -81,6 +299,454 @@ func (m *ProbeConf) GetProxyUrl() string {
 	return ""
 }
 
+func (m *ProbeConf) GetMaxThisUpdateAgeSec() int32 {
+	if m != nil && m.MaxThisUpdateAgeSec != nil {
+		return *m.MaxThisUpdateAgeSec
+	}
+	return Default_ProbeConf_MaxThisUpdateAgeSec
+}
+
+func (m *ProbeConf) GetFailOnStaleResponse() bool {
+	if m != nil && m.FailOnStaleResponse != nil {
+		return *m.FailOnStaleResponse
+	}
+	return Default_ProbeConf_FailOnStaleResponse
+}
+
+func (m *ProbeConf) GetMaxRequestsPerServerPerSec() float64 {
+	if m != nil && m.MaxRequestsPerServerPerSec != nil {
+		return *m.MaxRequestsPerServerPerSec
+	}
+	return Default_ProbeConf_MaxRequestsPerServerPerSec
+}
+
+func (m *ProbeConf) GetOcspClientCertFile() string {
+	if m != nil && m.OcspClientCertFile != nil {
+		return *m.OcspClientCertFile
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetOcspClientKeyFile() string {
+	if m != nil && m.OcspClientKeyFile != nil {
+		return *m.OcspClientKeyFile
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetDryRun() bool {
+	if m != nil && m.DryRun != nil {
+		return *m.DryRun
+	}
+	return Default_ProbeConf_DryRun
+}
+
+func (m *ProbeConf) GetBatchOcspRequests() bool {
+	if m != nil && m.BatchOcspRequests != nil {
+		return *m.BatchOcspRequests
+	}
+	return Default_ProbeConf_BatchOcspRequests
+}
+
+func (m *ProbeConf) GetPrimaryServerOnly() bool {
+	if m != nil && m.PrimaryServerOnly != nil {
+		return *m.PrimaryServerOnly
+	}
+	return Default_ProbeConf_PrimaryServerOnly
+}
+
+func (m *ProbeConf) GetFailoverThreshold() int32 {
+	if m != nil && m.FailoverThreshold != nil {
+		return *m.FailoverThreshold
+	}
+	return Default_ProbeConf_FailoverThreshold
+}
+
+func (m *ProbeConf) GetCheckChainOcsp() bool {
+	if m != nil && m.CheckChainOcsp != nil {
+		return *m.CheckChainOcsp
+	}
+	return Default_ProbeConf_CheckChainOcsp
+}
+
+func (m *ProbeConf) GetCacheDir() string {
+	if m != nil && m.CacheDir != nil {
+		return *m.CacheDir
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetIpVersion() string {
+	if m != nil && m.IpVersion != nil {
+		return *m.IpVersion
+	}
+	return Default_ProbeConf_IpVersion
+}
+
+func (m *ProbeConf) GetUseHttp2() bool {
+	if m != nil && m.UseHttp2 != nil {
+		return *m.UseHttp2
+	}
+	return false
+}
+
+func (m *ProbeConf) GetParallelOcspServers() bool {
+	if m != nil && m.ParallelOcspServers != nil {
+		return *m.ParallelOcspServers
+	}
+	return Default_ProbeConf_ParallelOcspServers
+}
+
+func (m *ProbeConf) GetRequireServerAuthEku() bool {
+	if m != nil && m.RequireServerAuthEku != nil {
+		return *m.RequireServerAuthEku
+	}
+	return false
+}
+
+func (m *ProbeConf) GetDnsCacheTtlSec() int32 {
+	if m != nil && m.DnsCacheTtlSec != nil {
+		return *m.DnsCacheTtlSec
+	}
+	return Default_ProbeConf_DnsCacheTtlSec
+}
+
+func (m *ProbeConf) GetEvPolicyOids() []string {
+	if m != nil {
+		return m.EvPolicyOids
+	}
+	return nil
+}
+
+func (m *ProbeConf) GetMaxResponseAgeSec() int32 {
+	if m != nil && m.MaxResponseAgeSec != nil {
+		return *m.MaxResponseAgeSec
+	}
+	return Default_ProbeConf_MaxResponseAgeSec
+}
+
+func (m *ProbeConf) GetMinNextUpdateSec() int32 {
+	if m != nil && m.MinNextUpdateSec != nil {
+		return *m.MinNextUpdateSec
+	}
+	return Default_ProbeConf_MinNextUpdateSec
+}
+
+func (m *ProbeConf) GetMaxClockSkewSec() int32 {
+	if m != nil && m.MaxClockSkewSec != nil {
+		return *m.MaxClockSkewSec
+	}
+	return Default_ProbeConf_MaxClockSkewSec
+}
+
+func (m *ProbeConf) GetCertExpiryWarningDays() int32 {
+	if m != nil && m.CertExpiryWarningDays != nil {
+		return *m.CertExpiryWarningDays
+	}
+	return Default_ProbeConf_CertExpiryWarningDays
+}
+
+func (m *ProbeConf) GetCertExpiryCriticalDays() int32 {
+	if m != nil && m.CertExpiryCriticalDays != nil {
+		return *m.CertExpiryCriticalDays
+	}
+	return Default_ProbeConf_CertExpiryCriticalDays
+}
+
+func (m *ProbeConf) GetRevocationWebhookUrl() string {
+	if m != nil && m.RevocationWebhookUrl != nil {
+		return *m.RevocationWebhookUrl
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetWebhookTimeoutSec() int32 {
+	if m != nil && m.WebhookTimeoutSec != nil {
+		return *m.WebhookTimeoutSec
+	}
+	return Default_ProbeConf_WebhookTimeoutSec
+}
+
+func (m *ProbeConf) GetRevocationAlertCooldownSec() int32 {
+	if m != nil && m.RevocationAlertCooldownSec != nil {
+		return *m.RevocationAlertCooldownSec
+	}
+	return Default_ProbeConf_RevocationAlertCooldownSec
+}
+
+func (m *ProbeConf) GetHttpUserAgent() string {
+	if m != nil && m.HttpUserAgent != nil {
+		return *m.HttpUserAgent
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetMinTlsVersion() string {
+	if m != nil && m.MinTlsVersion != nil {
+		return *m.MinTlsVersion
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetRootCaCertDir() string {
+	if m != nil && m.RootCaCertDir != nil {
+		return *m.RootCaCertDir
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetVerifyTargetCertificate() bool {
+	if m != nil && m.VerifyTargetCertificate != nil {
+		return *m.VerifyTargetCertificate
+	}
+	return false
+}
+
+func (m *ProbeConf) GetEnableResponseDecompression() bool {
+	if m != nil && m.EnableResponseDecompression != nil {
+		return *m.EnableResponseDecompression
+	}
+	return false
+}
+
+func (m *ProbeConf) GetRequestMethod() string {
+	if m != nil && m.RequestMethod != nil {
+		return *m.RequestMethod
+	}
+	return Default_ProbeConf_RequestMethod
+}
+
+func (m *ProbeConf) GetOcspFallbackUrls() []string {
+	if m != nil {
+		return m.OcspFallbackUrls
+	}
+	return nil
+}
+
+func (m *ProbeConf) GetFallbackThreshold() int32 {
+	if m != nil && m.FallbackThreshold != nil {
+		return *m.FallbackThreshold
+	}
+	return Default_ProbeConf_FallbackThreshold
+}
+
+func (m *ProbeConf) GetCertDownloadBreakerThreshold() int32 {
+	if m != nil && m.CertDownloadBreakerThreshold != nil {
+		return *m.CertDownloadBreakerThreshold
+	}
+	return Default_ProbeConf_CertDownloadBreakerThreshold
+}
+
+func (m *ProbeConf) GetCertDownloadBreakerCooldownSec() int32 {
+	if m != nil && m.CertDownloadBreakerCooldownSec != nil {
+		return *m.CertDownloadBreakerCooldownSec
+	}
+	return Default_ProbeConf_CertDownloadBreakerCooldownSec
+}
+
+func (m *ProbeConf) GetMaxCertDownloadBackoffSec() int32 {
+	if m != nil && m.MaxCertDownloadBackoffSec != nil {
+		return *m.MaxCertDownloadBackoffSec
+	}
+	return Default_ProbeConf_MaxCertDownloadBackoffSec
+}
+
+func (m *ProbeConf) GetSuccessWindowSize() int32 {
+	if m != nil && m.SuccessWindowSize != nil {
+		return *m.SuccessWindowSize
+	}
+	return Default_ProbeConf_SuccessWindowSize
+}
+
+func (m *ProbeConf) GetLogFormat() string {
+	if m != nil && m.LogFormat != nil {
+		return *m.LogFormat
+	}
+	return Default_ProbeConf_LogFormat
+}
+
+func (m *ProbeConf) GetUseNonce() bool {
+	if m != nil && m.UseNonce != nil {
+		return *m.UseNonce
+	}
+	return false
+}
+
+func (m *ProbeConf) GetRequireNonceEcho() bool {
+	if m != nil && m.RequireNonceEcho != nil {
+		return *m.RequireNonceEcho
+	}
+	return false
+}
+
+func (m *ProbeConf) GetDnsServerAddr() string {
+	if m != nil && m.DnsServerAddr != nil {
+		return *m.DnsServerAddr
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetTryLaterMaxBackoffSec() int32 {
+	if m != nil && m.TryLaterMaxBackoffSec != nil {
+		return *m.TryLaterMaxBackoffSec
+	}
+	return Default_ProbeConf_TryLaterMaxBackoffSec
+}
+
+func (m *ProbeConf) GetVaultAddr() string {
+	if m != nil && m.VaultAddr != nil {
+		return *m.VaultAddr
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetVaultPkiMount() string {
+	if m != nil && m.VaultPkiMount != nil {
+		return *m.VaultPkiMount
+	}
+	return Default_ProbeConf_VaultPkiMount
+}
+
+func (m *ProbeConf) GetVaultPkiRole() string {
+	if m != nil && m.VaultPkiRole != nil {
+		return *m.VaultPkiRole
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetFallbackToGet() bool {
+	if m != nil && m.FallbackToGet != nil {
+		return *m.FallbackToGet
+	}
+	return false
+}
+
+func (m *ProbeConf) GetCertUpdateWorkers() int32 {
+	if m != nil && m.CertUpdateWorkers != nil {
+		return *m.CertUpdateWorkers
+	}
+	return Default_ProbeConf_CertUpdateWorkers
+}
+
+func (m *ProbeConf) GetAvailabilityWindowSize() int32 {
+	if m != nil && m.AvailabilityWindowSize != nil {
+		return *m.AvailabilityWindowSize
+	}
+	return Default_ProbeConf_AvailabilityWindowSize
+}
+
+func (m *ProbeConf) GetStructuredRequestLogging() bool {
+	if m != nil && m.StructuredRequestLogging != nil {
+		return *m.StructuredRequestLogging
+	}
+	return false
+}
+
+func (m *ProbeConf) GetCheckStaple() bool {
+	if m != nil && m.CheckStaple != nil {
+		return *m.CheckStaple
+	}
+	return false
+}
+
+func (m *ProbeConf) GetMinCertChainLength() int32 {
+	if m != nil && m.MinCertChainLength != nil {
+		return *m.MinCertChainLength
+	}
+	return Default_ProbeConf_MinCertChainLength
+}
+
+func (m *ProbeConf) GetMaxCertChainLength() int32 {
+	if m != nil && m.MaxCertChainLength != nil {
+		return *m.MaxCertChainLength
+	}
+	return Default_ProbeConf_MaxCertChainLength
+}
+
+func (m *ProbeConf) GetUseHttpCaching() bool {
+	if m != nil && m.UseHttpCaching != nil {
+		return *m.UseHttpCaching
+	}
+	return false
+}
+
+func (m *ProbeConf) GetStatusChangeCooldownSec() int32 {
+	if m != nil && m.StatusChangeCooldownSec != nil {
+		return *m.StatusChangeCooldownSec
+	}
+	return Default_ProbeConf_StatusChangeCooldownSec
+}
+
+func (m *ProbeConf) GetEnforceMustStaple() bool {
+	if m != nil && m.EnforceMustStaple != nil {
+		return *m.EnforceMustStaple
+	}
+	return false
+}
+
+func (m *ProbeConf) GetEwmaAlpha() float64 {
+	if m != nil && m.EwmaAlpha != nil {
+		return *m.EwmaAlpha
+	}
+	return Default_ProbeConf_EwmaAlpha
+}
+
+func (m *ProbeConf) GetProbeAllTargetIps() bool {
+	if m != nil && m.ProbeAllTargetIps != nil {
+		return *m.ProbeAllTargetIps
+	}
+	return false
+}
+
+func (m *ProbeConf) GetLabelOcspStatusAsString() bool {
+	if m != nil && m.LabelOcspStatusAsString != nil {
+		return *m.LabelOcspStatusAsString
+	}
+	return false
+}
+
+func (m *ProbeConf) GetRfc5019Mode() bool {
+	if m != nil && m.Rfc5019Mode != nil {
+		return *m.Rfc5019Mode
+	}
+	return false
+}
+
+func (m *ProbeConf) GetMaxAiaRedirects() int32 {
+	if m != nil && m.MaxAiaRedirects != nil {
+		return *m.MaxAiaRedirects
+	}
+	return Default_ProbeConf_MaxAiaRedirects
+}
+
+func (m *ProbeConf) GetCertSourceS3Bucket() string {
+	if m != nil && m.CertSourceS3Bucket != nil {
+		return *m.CertSourceS3Bucket
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetCertSourceS3Key() string {
+	if m != nil && m.CertSourceS3Key != nil {
+		return *m.CertSourceS3Key
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetCertSourceS3Region() string {
+	if m != nil && m.CertSourceS3Region != nil {
+		return *m.CertSourceS3Region
+	}
+	return ""
+}
+
+func (m *ProbeConf) GetCertSourceS3Endpoint() string {
+	if m != nil && m.CertSourceS3Endpoint != nil {
+		return *m.CertSourceS3Endpoint
+	}
+	return ""
+}
+
 func (m *ProbeConf) GetIntervalBetweenTargetsMsec() int32 {
 	if m != nil && m.IntervalBetweenTargetsMsec != nil {
 		return *m.IntervalBetweenTargetsMsec
@@ -112,24 +778,119 @@ func init() {
 func init() { proto.RegisterFile("ocsp/ocsp.proto", fileDescriptor_f6c5c913ab05ed9e) }
 
 var fileDescriptor_f6c5c913ab05ed9e = []byte{
-	// 304 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x91, 0x41, 0x4b, 0xc3, 0x30,
-	0x14, 0x80, 0xe9, 0x70, 0x60, 0xe3, 0x61, 0x92, 0x53, 0x99, 0x13, 0x86, 0xa7, 0x89, 0x98, 0x76,
-	0x1e, 0x3c, 0x0c, 0x4f, 0x53, 0x11, 0x0f, 0x83, 0x11, 0xf4, 0xe2, 0x25, 0xac, 0xd9, 0xeb, 0x16,
-	0xe8, 0x9a, 0xfa, 0x92, 0x4e, 0xfd, 0x87, 0xfe, 0x15, 0xff, 0x85, 0x24, 0xb1, 0xa3, 0x5e, 0xda,
-	0xf7, 0xf2, 0xbe, 0xf7, 0xc2, 0xfb, 0x42, 0x06, 0x5a, 0x9a, 0x3a, 0x75, 0x1f, 0x56, 0xa3, 0xb6,
-	0x9a, 0x1e, 0xb9, 0x78, 0x78, 0xb7, 0x51, 0x76, 0xdb, 0xe4, 0x4c, 0xea, 0x5d, 0x2a, 0x4b, 0xdd,
-	0xac, 0x6b, 0xd4, 0x39, 0xe0, 0xbf, 0xd8, 0xff, 0x4c, 0xea, 0xdb, 0x52, 0xa9, 0xab, 0x42, 0x6d,
-	0xc2, 0x8c, 0x8b, 0x9f, 0x88, 0xc4, 0x4b, 0x57, 0xbd, 0xd7, 0x55, 0x41, 0x9f, 0xc8, 0x48, 0x02,
-	0x5a, 0x55, 0x28, 0xb9, 0xb2, 0x20, 0x10, 0x0a, 0x04, 0xb3, 0x15, 0xaa, 0xb2, 0x80, 0xfb, 0x55,
-	0x99, 0x44, 0xe3, 0x68, 0xd2, 0x9f, 0xf5, 0x6f, 0xb3, 0x2c, 0xcb, 0xf8, 0xb0, 0x83, 0xf2, 0x40,
-	0x3e, 0xff, 0x81, 0xf4, 0x8c, 0xc4, 0x35, 0xea, 0xcf, 0x2f, 0xd1, 0x60, 0x99, 0xf4, 0xc6, 0xd1,
-	0x24, 0xe6, 0xc7, 0xfe, 0xe0, 0x15, 0x4b, 0xfa, 0x48, 0xce, 0xdb, 0x89, 0x22, 0x07, 0xfb, 0x01,
-	0x50, 0x09, 0xbb, 0xc2, 0x0d, 0x58, 0x23, 0x76, 0x06, 0x64, 0x92, 0xfb, 0x6b, 0x7a, 0xd3, 0x8c,
-	0x0f, 0x5b, 0x70, 0x1e, 0xb8, 0x97, 0x80, 0x2d, 0x0c, 0x48, 0x9a, 0x12, 0x8a, 0xf0, 0xde, 0x80,
-	0xb1, 0x46, 0xd4, 0x80, 0xc2, 0x2f, 0x99, 0x48, 0xdf, 0x1b, 0x4d, 0xf9, 0x69, 0x5b, 0x5c, 0x02,
-	0xfa, 0x0d, 0x67, 0x0b, 0x42, 0x9c, 0xb1, 0x00, 0xd2, 0x11, 0xeb, 0x18, 0x62, 0xc1, 0x10, 0xf3,
-	0xe0, 0x03, 0x14, 0xc9, 0xb7, 0x5b, 0xf5, 0xe4, 0x66, 0xc0, 0xbc, 0xef, 0x83, 0x21, 0x1e, 0xbb,
-	0xdc, 0xa7, 0xf3, 0xab, 0xb7, 0xcb, 0x8e, 0xfa, 0x35, 0xaa, 0x3d, 0x54, 0x60, 0xbb, 0xde, 0xaf,
-	0x0f, 0x2f, 0xf6, 0x1b, 0x00, 0x00, 0xff, 0xff, 0xc9, 0x1d, 0x76, 0xea, 0xbd, 0x01, 0x00, 0x00,
+	// 1823 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x57, 0x7b, 0x73, 0x14, 0x37,
+	0x12, 0x2f, 0x07, 0x48, 0xb0, 0x02, 0x7e, 0x8c, 0x31, 0x08, 0x83, 0xc1, 0x21, 0xb9, 0xc4, 0x39,
+	0x82, 0xbd, 0xb6, 0x79, 0x24, 0x0e, 0xdc, 0x9d, 0xbd, 0x3c, 0x92, 0x0a, 0x8e, 0x5d, 0xb3, 0xe6,
+	0xa8, 0xba, 0x7f, 0x54, 0xda, 0x99, 0xde, 0x5d, 0xd5, 0x6a, 0xa5, 0x39, 0x49, 0xb3, 0x8f, 0x7c,
+	0xc2, 0xfb, 0x56, 0x77, 0xd5, 0xad, 0x19, 0xef, 0x38, 0xc7, 0x3f, 0xf6, 0x4e, 0xff, 0x7e, 0xdd,
+	0x52, 0x3f, 0x25, 0xb1, 0x65, 0x9b, 0xf9, 0x62, 0x17, 0xff, 0xec, 0x14, 0xce, 0x06, 0x9b, 0x5c,
+	0xc5, 0xdf, 0x1b, 0x2f, 0xfb, 0x2a, 0x0c, 0xca, 0xee, 0x4e, 0x66, 0x47, 0xbb, 0x99, 0xb6, 0x65,
+	0x5e, 0x38, 0xdb, 0x05, 0x77, 0xe9, 0x37, 0xfd, 0xf3, 0xbb, 0xa4, 0xb6, 0x9b, 0x59, 0xd3, 0x53,
+	0xfd, 0x68, 0xe3, 0xd1, 0x7f, 0x37, 0xd8, 0xe2, 0x19, 0xa2, 0x6d, 0x6b, 0x7a, 0xc9, 0x3b, 0x76,
+	0x3f, 0x03, 0x17, 0x54, 0x4f, 0x65, 0x32, 0x80, 0x70, 0xd0, 0x73, 0xe0, 0x07, 0x42, 0x99, 0x00,
+	0x6e, 0x2c, 0x35, 0x5f, 0xd8, 0x5a, 0xd8, 0xbe, 0x76, 0x78, 0xed, 0x79, 0xab, 0xd5, 0x6a, 0xa5,
+	0x1b, 0x0d, 0x6a, 0x1a, 0x99, 0xbf, 0x56, 0xc4, 0xe4, 0x1e, 0x5b, 0x2c, 0x9c, 0x9d, 0xce, 0x44,
+	0xe9, 0x34, 0xff, 0x6c, 0x6b, 0x61, 0x7b, 0x31, 0xbd, 0x4e, 0x82, 0x0f, 0x4e, 0x27, 0x2f, 0xd8,
+	0x9d, 0x91, 0x9c, 0x8a, 0x30, 0x50, 0x5e, 0x94, 0x45, 0x8e, 0x2b, 0xc9, 0x3e, 0x08, 0x0f, 0x19,
+	0xbf, 0x42, 0x0b, 0x2c, 0xb4, 0xd2, 0xb5, 0x91, 0x9c, 0x9e, 0x0f, 0x94, 0xff, 0x40, 0xf8, 0x51,
+	0x1f, 0x3a, 0x90, 0x25, 0x87, 0xec, 0x76, 0x4f, 0x2a, 0x2d, 0xac, 0x11, 0x3e, 0x48, 0x8d, 0x1b,
+	0xf4, 0x85, 0x35, 0x1e, 0xf8, 0xd5, 0xad, 0x85, 0xed, 0xeb, 0x87, 0xd7, 0x7a, 0x52, 0x7b, 0x48,
+	0xd7, 0x90, 0x74, 0x6a, 0x3a, 0x48, 0x49, 0x2b, 0x46, 0xf2, 0x96, 0x3d, 0xc4, 0x45, 0x1d, 0xfc,
+	0xbb, 0x04, 0x1f, 0xbc, 0x28, 0xc0, 0x09, 0x0f, 0x6e, 0x0c, 0xae, 0xfa, 0x99, 0xf1, 0x6b, 0x5b,
+	0x0b, 0xdb, 0x0b, 0xb8, 0xf8, 0xc6, 0x48, 0x4e, 0xd3, 0x8a, 0x78, 0x06, 0xae, 0x43, 0x34, 0xfa,
+	0x91, 0x25, 0x7b, 0x6c, 0x1d, 0xc3, 0x2e, 0x32, 0xad, 0xc0, 0x04, 0x81, 0x31, 0x10, 0x3d, 0xa5,
+	0x81, 0x7f, 0x4e, 0x5e, 0x26, 0x08, 0xb6, 0x09, 0x6b, 0x83, 0x0b, 0x6f, 0x95, 0x86, 0x64, 0x97,
+	0xdd, 0x6a, 0xaa, 0x0c, 0x61, 0x16, 0x35, 0xbe, 0x20, 0x8d, 0xd5, 0xb9, 0xc6, 0x6f, 0x30, 0x23,
+	0x85, 0x07, 0xec, 0x8b, 0xdc, 0xcd, 0x84, 0x2b, 0x0d, 0xbf, 0xde, 0x74, 0xec, 0xf3, 0xdc, 0xcd,
+	0xd2, 0xd2, 0x24, 0xcf, 0xd8, 0x5a, 0x57, 0x86, 0x6c, 0x20, 0xc8, 0x6c, 0xed, 0x12, 0x5f, 0x6c,
+	0x72, 0x57, 0x89, 0x71, 0x9a, 0xf9, 0xa2, 0xf6, 0x04, 0xd5, 0x0a, 0xa7, 0x46, 0xd2, 0xcd, 0x6a,
+	0xcf, 0xad, 0xd1, 0x33, 0xce, 0x2e, 0xa9, 0x55, 0x8c, 0xe8, 0xf3, 0xa9, 0xd1, 0xb3, 0xa4, 0xc5,
+	0x12, 0x0c, 0xa8, 0x45, 0x85, 0x30, 0xc0, 0x34, 0x5b, 0x9d, 0xf3, 0x2f, 0x63, 0xa6, 0x0e, 0xd2,
+	0xd5, 0x1a, 0x3c, 0xaf, 0xb1, 0x64, 0x97, 0xad, 0x64, 0x03, 0xc8, 0x86, 0x22, 0x1b, 0x48, 0x65,
+	0x68, 0x97, 0xfc, 0x46, 0x73, 0x95, 0x25, 0x82, 0xdb, 0x88, 0xe2, 0x0e, 0xb1, 0x5c, 0x32, 0x99,
+	0x0d, 0x40, 0xe4, 0xca, 0xf1, 0x9b, 0xb1, 0x5c, 0x48, 0xf0, 0x5a, 0xb9, 0xe4, 0x11, 0x63, 0xaa,
+	0x10, 0x63, 0x70, 0x5e, 0x59, 0xc3, 0x97, 0x10, 0x3d, 0xbc, 0x22, 0xcd, 0x2c, 0x5d, 0x54, 0xc5,
+	0x3f, 0xa3, 0x14, 0x0d, 0x94, 0x1e, 0xc4, 0x20, 0x84, 0x62, 0x9f, 0x2f, 0xe3, 0x52, 0xe9, 0xf5,
+	0xd2, 0xc3, 0x2f, 0xf8, 0x9d, 0xfc, 0xc8, 0xd6, 0x0b, 0xe9, 0xa4, 0xd6, 0xa0, 0x63, 0xc4, 0xa2,
+	0xf7, 0x9e, 0xaf, 0xd0, 0x9e, 0xae, 0x06, 0x57, 0x42, 0xba, 0x56, 0x53, 0x70, 0x43, 0xd1, 0x7b,
+	0x8c, 0xd8, 0x1d, 0x8c, 0xae, 0x72, 0x50, 0x47, 0x4c, 0x96, 0x61, 0x20, 0x60, 0x58, 0xf2, 0x55,
+	0x5a, 0xe4, 0x56, 0x05, 0x47, 0x85, 0xa3, 0x32, 0x0c, 0xde, 0x0c, 0xcb, 0x64, 0x87, 0xad, 0xe6,
+	0xc6, 0x8b, 0xe8, 0x52, 0x08, 0x9a, 0xaa, 0x2b, 0xa1, 0x80, 0x5d, 0x39, 0x68, 0xb5, 0xd2, 0xa5,
+	0xdc, 0xf8, 0x36, 0x82, 0xe7, 0x41, 0x63, 0x4d, 0x7d, 0xc3, 0x96, 0x60, 0x2c, 0x0a, 0xab, 0x55,
+	0x36, 0x13, 0x56, 0xe5, 0x9e, 0xaf, 0x6d, 0x5d, 0xd9, 0x5e, 0x4c, 0x6f, 0xc0, 0xf8, 0x8c, 0x84,
+	0xa7, 0x2a, 0xf7, 0xc9, 0x3e, 0xbb, 0x15, 0x2b, 0x38, 0x56, 0xf4, 0x45, 0xcf, 0xdc, 0xaa, 0x7b,
+	0x66, 0x95, 0xca, 0x36, 0xa2, 0x55, 0xc7, 0xb4, 0xd8, 0xda, 0x48, 0x19, 0x61, 0x60, 0x1a, 0xea,
+	0x56, 0x43, 0x95, 0xf5, 0x5a, 0x65, 0x65, 0xa4, 0xcc, 0xef, 0x30, 0x0d, 0xb1, 0xcd, 0xa2, 0x46,
+	0x82, 0xab, 0x64, 0xda, 0x66, 0x43, 0xe1, 0x87, 0x30, 0x21, 0x85, 0xdb, 0xf3, 0xcd, 0x2f, 0x8f,
+	0xe4, 0xb4, 0x8d, 0x68, 0x67, 0x08, 0x13, 0xd4, 0xf8, 0x99, 0x71, 0xea, 0x02, 0x98, 0x16, 0xca,
+	0xcd, 0xc4, 0x44, 0x3a, 0xa3, 0x4c, 0x5f, 0xe4, 0x72, 0xe6, 0xf9, 0x1d, 0xd2, 0xfb, 0xec, 0xa0,
+	0x95, 0xae, 0x23, 0xe7, 0x0d, 0x51, 0x3e, 0x46, 0xc6, 0x6b, 0x39, 0xf3, 0xc9, 0x4b, 0x76, 0xb7,
+	0xa9, 0x9c, 0x39, 0x15, 0x54, 0x26, 0x75, 0xd4, 0xe6, 0x71, 0x9b, 0x2f, 0xd2, 0xdb, 0x73, 0xe5,
+	0x76, 0xc5, 0x20, 0xed, 0xa7, 0xec, 0xb6, 0x83, 0xb1, 0xcd, 0x64, 0x50, 0xd6, 0x88, 0x09, 0x74,
+	0x07, 0xd6, 0x0e, 0x69, 0xe6, 0xdc, 0xa5, 0x22, 0xba, 0x35, 0x47, 0x3f, 0x46, 0x10, 0xe7, 0xcf,
+	0x3e, 0x5b, 0xab, 0xa9, 0x41, 0x8d, 0xc0, 0x96, 0x81, 0x7c, 0xdc, 0x88, 0x7b, 0xdd, 0x6b, 0xa5,
+	0xab, 0x15, 0x7c, 0x1e, 0x51, 0x74, 0xf2, 0x1d, 0xdb, 0x6c, 0xac, 0x24, 0x35, 0xee, 0x39, 0xb3,
+	0x56, 0xe7, 0x76, 0x62, 0x48, 0xfb, 0x1e, 0x69, 0x5f, 0x3d, 0x78, 0x8e, 0x93, 0x71, 0x4e, 0x3d,
+	0x42, 0x66, 0xbb, 0x22, 0xa2, 0xa1, 0x6f, 0xd9, 0x32, 0x56, 0xa9, 0x28, 0x3d, 0x56, 0x53, 0x1f,
+	0x4c, 0xe0, 0xf7, 0x69, 0xaf, 0x37, 0x51, 0xfc, 0xc1, 0x83, 0x3b, 0x42, 0x21, 0xf2, 0x30, 0x73,
+	0x41, 0xfb, 0x8b, 0xd2, 0xdf, 0x8c, 0xbc, 0x91, 0x32, 0xe7, 0xda, 0xd7, 0x95, 0xff, 0x1d, 0x5b,
+	0x71, 0xd6, 0x06, 0x91, 0xc9, 0x38, 0x8b, 0xb0, 0x83, 0x1e, 0x44, 0x22, 0xca, 0xdb, 0x12, 0xc7,
+	0x10, 0xb6, 0xd1, 0x21, 0xbb, 0x3b, 0x06, 0xa7, 0x7a, 0x33, 0x11, 0xa4, 0xeb, 0x43, 0x10, 0x8d,
+	0xf1, 0xcd, 0x1f, 0x52, 0x35, 0xdf, 0x89, 0x84, 0x73, 0xc2, 0xdb, 0x73, 0x38, 0x39, 0x66, 0x9b,
+	0x60, 0x64, 0xb7, 0x31, 0x71, 0x45, 0x0e, 0x99, 0x1d, 0x15, 0x0e, 0x3c, 0x6d, 0x6d, 0x8b, 0xf4,
+	0xef, 0x45, 0x52, 0x5d, 0x83, 0xaf, 0x9b, 0x94, 0xe4, 0x31, 0x5b, 0xaa, 0x26, 0x95, 0x18, 0x41,
+	0x18, 0xd8, 0x9c, 0x7f, 0x45, 0xad, 0x7c, 0xf5, 0xec, 0xb4, 0x73, 0x9e, 0xde, 0xac, 0xb0, 0x13,
+	0x82, 0x92, 0x1f, 0x18, 0x0d, 0x52, 0xd1, 0x93, 0x5a, 0x77, 0x65, 0x46, 0x39, 0xf5, 0xfc, 0x11,
+	0x75, 0xc5, 0x0a, 0x22, 0x6f, 0x2b, 0xe0, 0x83, 0xd3, 0x3e, 0x4e, 0xa8, 0x8a, 0x38, 0x9f, 0x50,
+	0x5f, 0x37, 0x26, 0x54, 0x04, 0xe7, 0x13, 0xea, 0x17, 0xf6, 0x30, 0x46, 0xcb, 0x4e, 0x8c, 0xb6,
+	0x32, 0x17, 0x5d, 0x07, 0x72, 0x78, 0x69, 0xc0, 0x7d, 0x13, 0xd5, 0x9f, 0xa5, 0x74, 0x24, 0xbe,
+	0xae, 0x88, 0xc7, 0x91, 0x37, 0xb7, 0x74, 0xca, 0x1e, 0x7d, 0xda, 0xd2, 0xa5, 0xea, 0xf8, 0xcb,
+	0xbc, 0x7f, 0x1e, 0x7c, 0xc2, 0x5c, 0xb3, 0x40, 0xde, 0xb2, 0x4d, 0x6a, 0xc0, 0xcb, 0x46, 0x65,
+	0x36, 0xb4, 0xbd, 0x1e, 0xd9, 0xfa, 0xb6, 0x51, 0x69, 0x77, 0xb1, 0x19, 0x9b, 0xf6, 0x22, 0x0f,
+	0xed, 0xec, 0xb3, 0x35, 0x5f, 0x66, 0x19, 0x78, 0x2f, 0x26, 0xca, 0xe4, 0x76, 0x22, 0xbc, 0xfa,
+	0x03, 0xf8, 0x77, 0xf3, 0x2a, 0xaf, 0xe0, 0x8f, 0x84, 0x76, 0xd4, 0x1f, 0x90, 0x7c, 0xcd, 0x98,
+	0xb6, 0x7d, 0xd1, 0xb3, 0x6e, 0x24, 0x03, 0xdf, 0x8e, 0xf9, 0x09, 0x30, 0x0d, 0xe9, 0xa2, 0xb6,
+	0xfd, 0xb7, 0x24, 0xae, 0x67, 0xad, 0xb1, 0x26, 0x03, 0xfe, 0xfd, 0xc5, 0xac, 0xfd, 0x1d, 0xbf,
+	0x31, 0x71, 0xf5, 0xc4, 0x24, 0x82, 0x80, 0x6c, 0x60, 0xf9, 0x5f, 0x89, 0xb5, 0x52, 0x21, 0xc4,
+	0x7c, 0x93, 0x0d, 0x2c, 0x16, 0x39, 0x0e, 0xca, 0x7a, 0xb6, 0xe6, 0xb9, 0xe3, 0x8f, 0x63, 0xed,
+	0xe6, 0xc6, 0x57, 0x33, 0x35, 0xcf, 0x5d, 0xf2, 0x8a, 0xdd, 0x0d, 0x6e, 0x26, 0xb4, 0x0c, 0xe0,
+	0x04, 0x46, 0xa7, 0x19, 0x8f, 0x1f, 0x62, 0x6c, 0x31, 0x1c, 0xeb, 0xc1, 0xcd, 0xde, 0x23, 0xe9,
+	0x44, 0x4e, 0x1b, 0xa1, 0xd8, 0x64, 0x6c, 0x2c, 0x4b, 0x1d, 0xe2, 0x0a, 0x4f, 0x68, 0x85, 0x45,
+	0x92, 0x90, 0xf5, 0xc7, 0x6c, 0x39, 0xc2, 0xc5, 0x50, 0x89, 0x91, 0x2d, 0x4d, 0xe0, 0x3b, 0xf1,
+	0x94, 0x29, 0x86, 0x2a, 0xbd, 0x49, 0xd8, 0xd9, 0x50, 0x9d, 0x20, 0x82, 0xb3, 0x7a, 0x4e, 0x76,
+	0x56, 0x03, 0xdf, 0x25, 0x7b, 0x37, 0x6a, 0x5a, 0x6a, 0x35, 0xa0, 0x63, 0xf3, 0x8a, 0xb4, 0xa2,
+	0x0f, 0x81, 0xb7, 0x28, 0x06, 0x37, 0x2f, 0x6a, 0xd1, 0xbe, 0x83, 0x90, 0xec, 0xb1, 0x35, 0x4a,
+	0x74, 0x35, 0x9b, 0x27, 0xd6, 0x0d, 0xf1, 0x60, 0xda, 0xab, 0x6b, 0x6f, 0x15, 0xd1, 0x38, 0x9c,
+	0x3f, 0x46, 0x2c, 0x79, 0xc5, 0xb8, 0x1c, 0x4b, 0xa5, 0x65, 0x57, 0x69, 0x15, 0x66, 0x97, 0x92,
+	0xbb, 0x1f, 0x43, 0xb1, 0xd7, 0x6a, 0xa5, 0xb7, 0x9b, 0xa4, 0x46, 0x8a, 0x5f, 0xb2, 0x0d, 0x1f,
+	0x5c, 0x99, 0x85, 0xd2, 0x41, 0x5e, 0xdf, 0x1d, 0x84, 0xb6, 0xfd, 0xbe, 0x32, 0x7d, 0x7e, 0x40,
+	0x9b, 0xe4, 0x73, 0x46, 0x75, 0x79, 0x78, 0x1f, 0xf1, 0xe4, 0x2b, 0x76, 0x23, 0x9e, 0xec, 0x3e,
+	0xc8, 0x42, 0x03, 0x7f, 0x4a, 0xfc, 0x2f, 0x49, 0xd6, 0x21, 0x51, 0xf2, 0x94, 0xad, 0xe3, 0xe0,
+	0x22, 0xb7, 0xe2, 0xf9, 0xaf, 0xc1, 0xf4, 0xc3, 0x80, 0x3f, 0xab, 0x0f, 0x9d, 0x64, 0xa4, 0x0c,
+	0x16, 0x2d, 0x9d, 0xff, 0xef, 0x09, 0x24, 0x2d, 0x39, 0xfd, 0x84, 0xd6, 0xf3, 0x3a, 0x14, 0x49,
+	0x55, 0xea, 0x4d, 0xad, 0x6d, 0xb6, 0x52, 0x1f, 0xfb, 0x74, 0xda, 0xa2, 0x0b, 0x2f, 0x68, 0x4b,
+	0x4b, 0xd5, 0xe9, 0xdf, 0x8e, 0xd2, 0xe4, 0x08, 0xdd, 0x96, 0xa1, 0xf4, 0x68, 0xdd, 0xf4, 0xe1,
+	0x72, 0x7b, 0xfe, 0xd8, 0x68, 0xa9, 0x3b, 0x91, 0xd7, 0x26, 0x5a, 0xb3, 0x31, 0x77, 0xd8, 0x1a,
+	0x98, 0x9e, 0x75, 0x19, 0x88, 0x51, 0xe9, 0x43, 0x1d, 0x82, 0x9f, 0x68, 0xbd, 0xd5, 0x0a, 0x3a,
+	0x29, 0x7d, 0xa8, 0x02, 0xf1, 0x88, 0x31, 0x98, 0x8c, 0xa4, 0x90, 0xba, 0x18, 0x48, 0x7e, 0x48,
+	0x97, 0xcb, 0x2b, 0xad, 0x9d, 0xbd, 0x74, 0x11, 0xc5, 0x47, 0x28, 0xc5, 0xab, 0x21, 0xdd, 0xcd,
+	0x85, 0xd4, 0xba, 0x9e, 0xcb, 0xaa, 0xf0, 0xfc, 0xe7, 0x68, 0x94, 0xb0, 0x23, 0xad, 0xe3, 0x44,
+	0xfe, 0xb5, 0xc0, 0xf3, 0xf2, 0x9e, 0x96, 0xdd, 0x8b, 0x8b, 0x4c, 0x74, 0x49, 0x7a, 0xe1, 0x83,
+	0x43, 0xe7, 0x5f, 0xc6, 0x39, 0x4e, 0x14, 0xba, 0xc8, 0x10, 0xe1, 0xc8, 0x77, 0x82, 0xab, 0xd2,
+	0xe7, 0x7a, 0xd9, 0xb3, 0xd6, 0xde, 0x4f, 0x62, 0x64, 0x73, 0xe0, 0xaf, 0x62, 0xfa, 0x2a, 0xd9,
+	0x89, 0xcd, 0x21, 0x79, 0xc2, 0xf0, 0x1a, 0x21, 0xa4, 0x92, 0xc2, 0x41, 0xae, 0x1c, 0x64, 0xc1,
+	0xf3, 0xbf, 0xd5, 0xa3, 0x14, 0x0f, 0xff, 0x23, 0x25, 0xd3, 0x1a, 0xc1, 0xeb, 0x30, 0xe5, 0xcc,
+	0xdb, 0x12, 0x03, 0xe3, 0x0f, 0x44, 0xb7, 0xcc, 0x86, 0x10, 0xf8, 0xdf, 0xe3, 0x75, 0x18, 0xc1,
+	0x0e, 0x61, 0x9d, 0x83, 0x63, 0x42, 0x92, 0xc7, 0x2c, 0xf9, 0x93, 0xca, 0x10, 0x66, 0xfc, 0x1f,
+	0xc4, 0x5f, 0x6e, 0xf2, 0x7f, 0x83, 0xd9, 0x27, 0xec, 0x3b, 0xe8, 0xe3, 0x89, 0x73, 0xf4, 0xff,
+	0xf6, 0x53, 0x42, 0xf0, 0xd2, 0xf6, 0x27, 0x15, 0x30, 0x79, 0x61, 0x95, 0x09, 0xfc, 0x38, 0xde,
+	0x0a, 0x9a, 0x4a, 0x6f, 0x2a, 0x2c, 0x79, 0xc3, 0x36, 0xeb, 0x77, 0x8e, 0xe8, 0x42, 0x98, 0x00,
+	0x98, 0x2a, 0x23, 0x5e, 0x8c, 0xb0, 0x48, 0xba, 0x17, 0x93, 0x73, 0xa3, 0x26, 0x1e, 0x47, 0x5e,
+	0x4c, 0x8f, 0x3f, 0xf1, 0x90, 0x25, 0xbb, 0x71, 0x00, 0x5e, 0xbc, 0x31, 0x28, 0x85, 0x3c, 0x8b,
+	0x01, 0xdc, 0x4b, 0x57, 0x6a, 0xf0, 0x0c, 0x1c, 0xbd, 0xbb, 0x0e, 0x4f, 0x18, 0xa3, 0x5c, 0x12,
+	0x31, 0xb9, 0xbf, 0xd3, 0x78, 0xb7, 0xed, 0xd0, 0x3f, 0xbf, 0x43, 0xc4, 0xd7, 0xd0, 0xe3, 0xff,
+	0xc1, 0x07, 0xd8, 0x97, 0xfb, 0xcb, 0x3b, 0xf4, 0x0a, 0xbc, 0x78, 0xb7, 0xa5, 0x8b, 0xf8, 0x4d,
+	0x9f, 0xc7, 0x8f, 0xff, 0xf5, 0x7d, 0xe3, 0x41, 0x98, 0x3b, 0x35, 0x06, 0x03, 0xa1, 0xf9, 0x1a,
+	0x7c, 0x72, 0xf1, 0x8e, 0xfc, 0xdf, 0x00, 0x4d, 0xaf, 0xf1, 0xd4, 0x53, 0x0e, 0x00, 0x00,
 }
@@ -0,0 +1,380 @@
+package ocsp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/probes/options"
+	"github.com/cloudprober/cloudprober/targets"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// newTestProbe returns an initialized probe for the targets, applying the
+// setup functions before Init.
+func newTestProbe(t *testing.T, conf *ProbeConf, eps []endpoint.Endpoint, setup ...func(*Probe)) *Probe {
+	t.Helper()
+
+	opts := options.DefaultOptions()
+	opts.ProbeConf = conf
+	opts.Targets = targets.StaticEndpoints(eps)
+
+	p := &Probe{}
+	for _, f := range setup {
+		f(p)
+	}
+	if err := p.Init("test", opts); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestOCSPGetURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		serverURL string
+		body      []byte
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "no escaping",
+			serverURL: "http://ocsp.example.com",
+			body:      []byte("abc"),
+			want:      "http://ocsp.example.com/YWJj",
+		},
+		{
+			name:      "plus, slash and padding",
+			serverURL: "http://ocsp.example.com",
+			body:      []byte{0xfb, 0xff},
+			want:      "http://ocsp.example.com/%2B%2F8%3D",
+		},
+		{
+			name:      "double padding",
+			serverURL: "http://ocsp.example.com/",
+			body:      []byte{0x00},
+			want:      "http://ocsp.example.com/AA%3D%3D",
+		},
+		{
+			name:      "server path",
+			serverURL: "http://ocsp.example.com/ocsp",
+			body:      []byte{0x30, 0x3e, 0xfb, 0xef, 0xbf},
+			want:      "http://ocsp.example.com/ocsp/MD77778%3D",
+		},
+		{
+			name:      "invalid server URL",
+			serverURL: "http://[::1",
+			body:      []byte("abc"),
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ocspGetURL(test.serverURL, test.body)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ocspGetURL() error = %v, want error: %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if got != test.want {
+				t.Errorf("ocspGetURL() = %s, want %s", got, test.want)
+			}
+
+			// Responders decode the last path segment.
+			u, err := url.Parse(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded, err := url.PathUnescape(path.Base(u.EscapedPath()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body, err := base64.StdEncoding.DecodeString(encoded); err != nil || !bytes.Equal(body, test.body) {
+				t.Errorf("request decoded from %s = %x, %v, want %x", got, body, err, test.body)
+			}
+		})
+	}
+}
+
+// TestOCSPGetURLRequest checks the GET URLs of real OCSP requests, known to
+// be accepted by cfssl's OCSP responder, whose encoding uses all characters
+// needing percent-encoding in the path (RFC 6960, appendix A.1).
+func TestOCSPGetURLRequest(t *testing.T) {
+	tests := []struct {
+		request  string
+		wantPath string
+	}{
+		{
+			request:  "MFQwUjBQME4wTDAJBgUrDgMCGgUABBQ55F6w46hhx/o6OXOHa+Yfe32YhgQU+3hPEvlgFYMsnxd/NBmzLjbqQYkCEwD6Wh0MaVKu9gJ3By9DI//xsd4=",
+			wantPath: "MFQwUjBQME4wTDAJBgUrDgMCGgUABBQ55F6w46hhx%2Fo6OXOHa%2BYfe32YhgQU%2B3hPEvlgFYMsnxd%2FNBmzLjbqQYkCEwD6Wh0MaVKu9gJ3By9DI%2F%2Fxsd4%3D",
+		},
+		{
+			request:  "MEMwQTA/MD0wOzAJBgUrDgMCGgUABBSwLsMRhyg1dJUwnXWk++D57lvgagQU6aQ/7p6l5vLV13lgPJOmLiSOl6oCAhJN",
+			wantPath: "MEMwQTA%2FMD0wOzAJBgUrDgMCGgUABBSwLsMRhyg1dJUwnXWk%2B%2BD57lvgagQU6aQ%2F7p6l5vLV13lgPJOmLiSOl6oCAhJN",
+		},
+	}
+
+	for _, test := range tests {
+		der, err := base64.StdEncoding.DecodeString(test.request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ocsp.ParseRequest(der); err != nil {
+			t.Fatalf("invalid OCSP request %s: %v", test.request, err)
+		}
+
+		got, err := ocspGetURL("http://ocsp.example.com", der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "http://ocsp.example.com/" + test.wantPath; got != want {
+			t.Errorf("ocspGetURL() = %s, want %s", got, want)
+		}
+	}
+}
+
+// TestNewOCSPRequestContentLength checks that OCSP POST requests aren't sent
+// chunked, which some OCSP servers reject.
+func TestNewOCSPRequestContentLength(t *testing.T) {
+	type received struct {
+		contentLength    int64
+		transferEncoding []string
+		body             []byte
+	}
+	receivedCh := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receivedCh <- received{r.ContentLength, r.TransferEncoding, body}
+	}))
+	defer server.Close()
+
+	p := newTestProbe(t, &ProbeConf{}, nil)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := bytes.Repeat([]byte{0x30}, 100)
+	req, err := p.newOCSPRequest(serverURL, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != http.MethodPost {
+		t.Fatalf("method = %s, want POST", req.Method)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	got := <-receivedCh
+	if got.contentLength != int64(len(body)) {
+		t.Errorf("Content-Length = %d, want %d", got.contentLength, len(body))
+	}
+	if len(got.transferEncoding) != 0 {
+		t.Errorf("Transfer-Encoding = %q, want none", got.transferEncoding)
+	}
+	if !bytes.Equal(got.body, body) {
+		t.Errorf("body = %x, want %x", got.body, body)
+	}
+}
+
+func TestDownloadServerCertificateAddress(t *testing.T) {
+	tests := []struct {
+		server         string
+		wantAddr       string
+		wantServerName string
+	}{
+		{"192.0.2.1", "192.0.2.1:443", "192.0.2.1"},
+		{"192.0.2.1:8443", "192.0.2.1:8443", "192.0.2.1"},
+		{"[::1]", "[::1]:443", "::1"},
+		{"[::1]:8443", "[::1]:8443", "::1"},
+		{"2001:db8::1", "[2001:db8::1]:443", "2001:db8::1"},
+		{"example.com", "example.com:443", "example.com"},
+		{"example.com:8443", "example.com:8443", "example.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.server, func(t *testing.T) {
+			var addr, serverName string
+			p := newTestProbe(t, &ProbeConf{}, nil, func(p *Probe) {
+				p.DialTLSHook = func(_, a string, config *tls.Config) (*tls.Conn, error) {
+					addr, serverName = a, config.ServerName
+					return nil, errors.New("not connecting")
+				}
+			})
+
+			if _, _, err := p.downloadServerCertificate(test.server, nil); err == nil {
+				t.Fatal("downloadServerCertificate() succeeded without a connection")
+			}
+			if addr != test.wantAddr {
+				t.Errorf("dialed %s, want %s", addr, test.wantAddr)
+			}
+			if serverName != test.wantServerName {
+				t.Errorf("server name = %s, want %s", serverName, test.wantServerName)
+			}
+		})
+	}
+}
+
+func TestRunProbeWithMockTransport(t *testing.T) {
+	const (
+		server    = "ocsp.example.com"
+		serverURL = "http://" + server
+	)
+	cert, issuer, issuerKey := newTestCertificates(t, serverURL)
+
+	tests := []struct {
+		name     string
+		response *mockResponse
+
+		wantSuccess int64
+		wantStatus  int
+	}{
+		{
+			name: "good",
+			response: &mockResponse{
+				contentType: "application/ocsp-response",
+				body:        newTestResponse(t, cert, issuer, issuerKey, ocsp.Good),
+			},
+			wantSuccess: 1,
+			wantStatus:  ocsp.Good,
+		},
+		{
+			name: "revoked",
+			response: &mockResponse{
+				contentType: "application/ocsp-response",
+				body:        newTestResponse(t, cert, issuer, issuerKey, ocsp.Revoked),
+			},
+			wantSuccess: 1,
+			wantStatus:  ocsp.Revoked,
+		},
+		{
+			name:       "server error",
+			response:   &mockResponse{status: http.StatusInternalServerError},
+			wantStatus: -1,
+		},
+		{
+			name:       "request error",
+			response:   &mockResponse{err: errors.New("connection reset")},
+			wantStatus: -1,
+		},
+		{
+			name:       "unknown URL",
+			wantStatus: -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			responses := make(map[string]mockResponse)
+			if test.response != nil {
+				responses[serverURL] = *test.response
+			}
+
+			target := endpoint.Endpoint{Name: "test.example.com"}
+			p := newTestProbe(t, &ProbeConf{}, []endpoint.Endpoint{target}, func(p *Probe) {
+				WithMockTransport(p, responses)
+			})
+			p.setCert(target.Key(), cert, issuer)
+
+			requests, err := p.ocspRequestForTarget(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := make(map[string]*probeResult)
+			if dispatched := p.runProbe(context.Background(), target, requests, results); dispatched != 1 {
+				t.Errorf("dispatched = %d, want 1", dispatched)
+			}
+
+			result, ok := results[server]
+			if !ok {
+				t.Fatalf("no result for %s", server)
+			}
+			if result.total != 1 {
+				t.Errorf("total = %d, want 1", result.total)
+			}
+			if result.success != test.wantSuccess {
+				t.Errorf("success = %d, want %d", result.success, test.wantSuccess)
+			}
+			if result.lastStatus != test.wantStatus {
+				t.Errorf("last status = %d, want %d", result.lastStatus, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRunProbeConnectTimeout(t *testing.T) {
+	// Answers no request until the client gives up. The request context
+	// is only canceled on disconnect once the body was read.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	cert, issuer, _ := newTestCertificates(t, server.URL)
+
+	tests := []struct {
+		name        string
+		blockDial   bool
+		wantTimeout int64
+	}{
+		{
+			name:        "connect",
+			blockDial:   true,
+			wantTimeout: 1,
+		},
+		{
+			name: "response",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			target := endpoint.Endpoint{Name: "test.example.com"}
+			p := newTestProbe(t, &ProbeConf{}, []endpoint.Endpoint{target})
+			p.opts.Timeout = 100 * time.Millisecond
+			if test.blockDial {
+				p.client.Transport.(*http.Transport).DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+			}
+			p.setCert(target.Key(), cert, issuer)
+
+			requests, err := p.ocspRequestForTarget(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := make(map[string]*probeResult)
+			p.runProbe(context.Background(), target, requests, results)
+
+			result, ok := results[server.Listener.Addr().String()]
+			if !ok {
+				t.Fatalf("no result for the OCSP server, got results for %d servers", len(results))
+			}
+			if result.timeouts != 1 {
+				t.Errorf("timeouts = %d, want 1", result.timeouts)
+			}
+			if result.connTimeouts != test.wantTimeout {
+				t.Errorf("connect timeouts = %d, want %d", result.connTimeouts, test.wantTimeout)
+			}
+		})
+	}
+}
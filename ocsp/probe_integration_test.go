@@ -0,0 +1,141 @@
+package ocsp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestFullProbeRoundTrip(t *testing.T) {
+	testProbeRoundTrip(t, ocsp.Good)
+}
+
+func TestFullProbeRoundTripRevoked(t *testing.T) {
+	testProbeRoundTrip(t, ocsp.Revoked)
+}
+
+// testProbeRoundTrip probes a leaf certificate issued by an RSA CA, whose
+// OCSP responder answers with the status, and checks the probe results.
+func testProbeRoundTrip(t *testing.T, status int) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test RSA CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The probe sends POST requests by default.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			_, _ = w.Write(ocsp.MalformedRequestErrorResponse)
+			return
+		}
+
+		now := time.Now()
+		template := ocsp.Response{
+			Status:       status,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   now.Add(-time.Minute),
+			NextUpdate:   now.Add(time.Hour),
+		}
+		if status == ocsp.Revoked {
+			template.RevokedAt = now.Add(-time.Hour)
+			template.RevocationReason = ocsp.KeyCompromise
+		}
+		resp, err := ocsp.CreateResponse(ca, ca, template, caKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		_, _ = w.Write(resp)
+	}))
+	defer server.Close()
+
+	leafKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test.example.com"},
+		DNSNames:     []string{"test.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		OCSPServer:   []string{server.URL},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(leafDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := endpoint.Endpoint{Name: "test.example.com"}
+	p := newTestProbe(t, &ProbeConf{}, []endpoint.Endpoint{target})
+	p.setCert(target.Key(), leaf, ca)
+
+	requests, err := p.ocspRequestForTarget(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := make(map[string]*probeResult)
+	p.runProbe(context.Background(), target, requests, results)
+
+	result, ok := results[server.Listener.Addr().String()]
+	if !ok {
+		t.Fatalf("no result for the OCSP server, got results for %d servers", len(results))
+	}
+	if result.total != 1 || result.success != 1 {
+		t.Errorf("total = %d, success = %d, want 1, 1", result.total, result.success)
+	}
+	if result.lastStatus != status {
+		t.Errorf("last status = %d, want %d", result.lastStatus, status)
+	}
+	if got := result.ocspCodes.GetKey(strconv.Itoa(status)); got != 1 {
+		t.Errorf("ocsp-code %d count = %d, want 1", status, got)
+	}
+	if latency, ok := result.latency.(*metrics.Float); !ok || latency.Float64() <= 0 {
+		t.Errorf("latency = %v, want > 0", result.latency)
+	}
+}
@@ -0,0 +1,194 @@
+package ocsp_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/drivenet/cloudprober-ocsp/internal/testhelper"
+	ocspprobe "github.com/drivenet/cloudprober-ocsp/ocsp"
+	"github.com/golang/protobuf/proto"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// blockingTransport answers no request, failing them when their context is
+// done.
+type blockingTransport struct{}
+
+func (blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+// htmlTransport answers all requests with an HTML error page, as served by
+// misconfigured load balancers in front of OCSP responders.
+type htmlTransport struct{}
+
+func (htmlTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/html"}},
+		Body:       io.NopCloser(strings.NewReader("<html><head><title>Service Unavailable</title></head><body>Try again later.</body></html>")),
+		Request:    req,
+	}, nil
+}
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name      string
+		conf      *ocspprobe.ProbeConf
+		server    func(t testing.TB) (*httptest.Server, *x509.Certificate, *x509.Certificate)
+		transport http.RoundTripper
+
+		wantSuccess, wantTimeouts, wantStale int64
+		wantStatus                           int
+	}{
+		{
+			name: "good",
+			server: func(t testing.TB) (*httptest.Server, *x509.Certificate, *x509.Certificate) {
+				return testhelper.NewMockOCSPServer(t, ocsp.Good, time.Hour)
+			},
+			wantSuccess: 1,
+			wantStatus:  ocsp.Good,
+		},
+		{
+			name: "revoked",
+			server: func(t testing.TB) (*httptest.Server, *x509.Certificate, *x509.Certificate) {
+				return testhelper.NewMockOCSPServer(t, ocsp.Revoked, time.Hour)
+			},
+			wantSuccess: 1,
+			wantStatus:  ocsp.Revoked,
+		},
+		{
+			name: "timeout",
+			server: func(t testing.TB) (*httptest.Server, *x509.Certificate, *x509.Certificate) {
+				return testhelper.NewMockOCSPServer(t, ocsp.Good, time.Hour)
+			},
+			transport:    blockingTransport{},
+			wantTimeouts: 1,
+			wantStatus:   -1,
+		},
+		{
+			name: "bad content type",
+			server: func(t testing.TB) (*httptest.Server, *x509.Certificate, *x509.Certificate) {
+				return testhelper.NewMockOCSPServer(t, ocsp.Good, time.Hour)
+			},
+			transport:  htmlTransport{},
+			wantStatus: -1,
+		},
+		{
+			name: "stale response",
+			conf: &ocspprobe.ProbeConf{
+				MaxThisUpdateAgeSec: proto.Int32(3600),
+				FailOnStaleResponse: proto.Bool(true),
+			},
+			server: func(t testing.TB) (*httptest.Server, *x509.Certificate, *x509.Certificate) {
+				return testhelper.NewStaleMockOCSPServer(t, ocsp.Good, 2*time.Hour, time.Hour)
+			},
+			wantStale:  1,
+			wantStatus: -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf := test.conf
+			if conf == nil {
+				conf = &ocspprobe.ProbeConf{}
+			}
+			_, ca, leaf := test.server(t)
+			target := endpoint.Endpoint{Name: "test.example.com"}
+
+			p := testhelper.NewTestProbe(t, conf, []endpoint.Endpoint{target}, test.transport)
+			p.SetCert(target, leaf, ca)
+
+			results, err := p.RunProbe(context.Background(), target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("got results for %d servers, want 1", len(results))
+			}
+
+			for server, result := range results {
+				if result.Total != 1 {
+					t.Errorf("%s: total = %d, want 1", server, result.Total)
+				}
+				if result.Success != test.wantSuccess {
+					t.Errorf("%s: success = %d, want %d", server, result.Success, test.wantSuccess)
+				}
+				if result.Timeouts != test.wantTimeouts {
+					t.Errorf("%s: timeouts = %d, want %d", server, result.Timeouts, test.wantTimeouts)
+				}
+				if result.StaleResponses != test.wantStale {
+					t.Errorf("%s: stale responses = %d, want %d", server, result.StaleResponses, test.wantStale)
+				}
+				if result.LastStatus != test.wantStatus {
+					t.Errorf("%s: last status = %d, want %d", server, result.LastStatus, test.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestCertDownloadBreakerTrip(t *testing.T) {
+	chain := testhelper.NewCertChain(t, "")
+	target := endpoint.Endpoint{Name: "test.example.com"}
+	conf := &ocspprobe.ProbeConf{
+		CertDownloadBreakerThreshold: proto.Int32(3),
+		// Retry failed downloads right away.
+		MaxCertDownloadBackoffSec: proto.Int32(0),
+	}
+
+	var (
+		failing atomic.Bool
+		dials   atomic.Int32
+	)
+	p := testhelper.NewTestProbe(t, conf, []endpoint.Endpoint{target}, nil,
+		testhelper.WithMockTLSServer(t, []*tls.Certificate{chain}),
+		func(p *ocspprobe.Probe) {
+			dial := p.DialTLSHook
+			p.DialTLSHook = func(network, addr string, config *tls.Config) (*tls.Conn, error) {
+				dials.Add(1)
+				if failing.Load() {
+					return nil, errors.New("connection reset")
+				}
+				return dial(network, addr, config)
+			}
+		})
+
+	p.UpdateCertificates()
+	if cert, _ := p.Cert(target); cert == nil || !cert.Equal(chain.Leaf) {
+		t.Fatal("certificate not downloaded")
+	}
+
+	failing.Store(true)
+	for i := range 3 {
+		if p.CertDownloadBreakerOpen(target) {
+			t.Fatalf("breaker open after %d failures, want 3", i)
+		}
+		p.UpdateCertificates()
+	}
+	if !p.CertDownloadBreakerOpen(target) {
+		t.Fatal("breaker not open after 3 failures")
+	}
+
+	// The last downloaded certificate is used while the breaker is open.
+	p.UpdateCertificates()
+	if got := dials.Load(); got != 4 {
+		t.Errorf("dialed %d times, want 4", got)
+	}
+	if cert, _ := p.Cert(target); cert == nil || !cert.Equal(chain.Leaf) {
+		t.Error("certificate not kept while the breaker is open")
+	}
+}
@@ -0,0 +1,67 @@
+package ocsp
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// oidOCSPNoCheck is the id-pkix-ocsp-nocheck extension of delegated OCSP
+// responder certificates (RFC 6960, section 4.2.2.2.1).
+var oidOCSPNoCheck = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 5}
+
+// responderID returns the common name of the response's responder name, or
+// the hex encoded responder key hash if the responder is identified by key.
+func responderID(resp *ocsp.Response) string {
+	if len(resp.RawResponderName) > 0 {
+		var rdn pkix.RDNSequence
+		if _, err := asn1.Unmarshal(resp.RawResponderName, &rdn); err == nil {
+			var name pkix.Name
+			name.FillFromRDNSequence(&rdn)
+			if name.CommonName != "" {
+				return name.CommonName
+			}
+			return name.String()
+		}
+	}
+	return hex.EncodeToString(resp.ResponderKeyHash)
+}
+
+// validateOCSPResponderCert checks the delegated OCSP responder certificate
+// against RFC 6960, section 2.6, and returns the violations found:
+// "missing_ocsp_signing", "not_signed_by_issuer" and "expired".
+func validateOCSPResponderCert(responder, issuer *x509.Certificate) []string {
+	var violations []string
+
+	if !hasOCSPSigning(responder) {
+		violations = append(violations, "missing_ocsp_signing")
+	}
+	if err := responder.CheckSignatureFrom(issuer); err != nil {
+		violations = append(violations, "not_signed_by_issuer")
+	}
+	if now := time.Now(); now.Before(responder.NotBefore) || now.After(responder.NotAfter) {
+		violations = append(violations, "expired")
+	}
+
+	return violations
+}
+
+// hasOCSPSigning returns true if the certificate has the OCSP signing
+// extended key usage or the id-pkix-ocsp-nocheck extension.
+func hasOCSPSigning(cert *x509.Certificate) bool {
+	for _, usage := range cert.ExtKeyUsage {
+		if usage == x509.ExtKeyUsageOCSPSigning {
+			return true
+		}
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidOCSPNoCheck) {
+			return true
+		}
+	}
+	return false
+}
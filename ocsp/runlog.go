@@ -0,0 +1,96 @@
+package ocsp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// probeRunLogEntry is a probe result logged when log_format is "json".
+type probeRunLogEntry struct {
+	TimestampUnix  int64   `json:"timestamp_unix"`
+	Target         string  `json:"target"`
+	OCSPServer     string  `json:"ocsp_server"`
+	HTTPStatus     int     `json:"http_status"`
+	OCSPStatus     int     `json:"ocsp_status"`
+	LatencyMs      float64 `json:"latency_ms"`
+	NextUpdateUnix int64   `json:"next_update_unix,omitempty"`
+	ThisUpdateUnix int64   `json:"this_update_unix,omitempty"`
+	CertSerial     string  `json:"cert_serial,omitempty"`
+	CertExpiryUnix int64   `json:"cert_expiry_unix,omitempty"`
+	IssuerKeyHash  string  `json:"issuer_key_hash,omitempty"`
+}
+
+// logRunEntry logs the result of an OCSP call to the target's OCSP server as
+// a single JSON line.
+func (p *Probe) logRunEntry(target endpoint.Endpoint, server string, result *callResult) {
+	entry := probeRunLogEntry{
+		TimestampUnix: time.Now().Unix(),
+		Target:        target.Name,
+		OCSPServer:    server,
+		HTTPStatus:    result.HTTPStatusCode,
+		OCSPStatus:    result.OCSPStatusCode,
+		LatencyMs:     float64(result.spent) / float64(time.Millisecond),
+	}
+	if resp := result.response; resp != nil {
+		entry.ThisUpdateUnix = resp.ThisUpdate.Unix()
+		if !resp.NextUpdate.IsZero() {
+			entry.NextUpdateUnix = resp.NextUpdate.Unix()
+		}
+	}
+	if cert, _ := p.getCert(target.Key()); cert != nil {
+		entry.CertSerial = cert.SerialNumber.Text(16)
+		entry.CertExpiryUnix = cert.NotAfter.Unix()
+	}
+	if result.CertSerial != "" {
+		entry.CertSerial = result.CertSerial
+		entry.IssuerKeyHash = result.IssuerKeyHash
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		p.l.Warningf("Target: %s, cannot marshal probe result: %v", target.Name, err)
+		return
+	}
+	p.l.Info(string(line))
+}
+
+// requestCertID returns the certificate serial number and the issuer key hash,
+// in hex, of the OCSP request, parsing its body or, for GET requests, its
+// URL. Empty strings are returned if the request can't be parsed.
+func requestCertID(req *http.Request) (serial, issuerKeyHash string) {
+	var (
+		body []byte
+		err  error
+	)
+	if req.Method == http.MethodGet {
+		var encoded string
+		if encoded, err = url.PathUnescape(path.Base(req.URL.EscapedPath())); err == nil {
+			body, err = base64.StdEncoding.DecodeString(encoded)
+		}
+	} else if req.GetBody != nil {
+		var rc io.ReadCloser
+		if rc, err = req.GetBody(); err == nil {
+			body, err = io.ReadAll(rc)
+			_ = rc.Close()
+		}
+	}
+	if err != nil || body == nil {
+		return "", ""
+	}
+
+	request, err := ocsp.ParseRequest(body)
+	if err != nil {
+		return "", ""
+	}
+	return request.SerialNumber.Text(16), hex.EncodeToString(request.IssuerKeyHash)
+}
@@ -0,0 +1,73 @@
+package ocsp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/pkg/errors"
+)
+
+// newS3Client returns the client of cert_source_s3_bucket, using the
+// standard AWS credential chain.
+func (p *Probe) newS3Client() (*s3.Client, error) {
+	var opts []func(*config.LoadOptions) error
+	if region := p.c.GetCertSourceS3Region(); region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		// S3-compatible stores usually don't support virtual-hosted-style
+		// bucket addressing.
+		if endpoint := p.c.GetCertSourceS3Endpoint(); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	}), nil
+}
+
+// downloadS3Certificate downloads the target's PEM certificate chain, leaf
+// first, from cert_source_s3_key with @target@ replaced by the target name.
+// The chain is returned as the peer certificates of the connection state.
+func (p *Probe) downloadS3Certificate(target endpoint.Endpoint) (*x509.Certificate, *tls.ConnectionState, error) {
+	key := strings.ReplaceAll(p.c.GetCertSourceS3Key(), "@target@", target.Name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.opts.Timeout)
+	defer cancel()
+
+	out, err := p.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.c.GetCertSourceS3Bucket()),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error getting s3://%s/%s", p.c.GetCertSourceS3Bucket(), key)
+	}
+	defer func() { _ = out.Body.Close() }()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certs, err := helpers.ParseCertificatesPEM(data)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error parsing s3://%s/%s", p.c.GetCertSourceS3Bucket(), key)
+	}
+	if len(certs) == 0 {
+		return nil, nil, errors.Wrap(errEmptyChain, key)
+	}
+
+	return certs[0], &tls.ConnectionState{PeerCertificates: certs}, nil
+}
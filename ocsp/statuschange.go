@@ -0,0 +1,55 @@
+package ocsp
+
+import (
+	"time"
+
+	"github.com/cloudprober/cloudprober/metrics"
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// recordStatus records the OCSP status of the target's certificate reported
+// by the OCSP server. It returns a status change event if the status differs
+// from the previous one and no event for the same new status was returned
+// within status_change_cooldown_sec, nil otherwise.
+func (p *Probe) recordStatus(ts time.Time, target endpoint.Endpoint, server string, status int) *metrics.EventMetrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := target.Key() + "|" + server
+	prev, ok := p.serverStatusHistory[key]
+	p.serverStatusHistory[key] = status
+	if !ok || prev == status {
+		return nil
+	}
+
+	if status == ocsp.Good {
+		for _, s := range []int{ocsp.Revoked, ocsp.Unknown, ocsp.ServerFailed} {
+			delete(p.lastStatusChangeAlert, key+"|"+ocspStatusString(s))
+		}
+	}
+
+	alertKey := key + "|" + ocspStatusString(status)
+	cooldown := time.Duration(p.c.GetStatusChangeCooldownSec()) * time.Second
+	if last, ok := p.lastStatusChangeAlert[alertKey]; ok && ts.Sub(last) < cooldown {
+		return nil
+	}
+	p.lastStatusChangeAlert[alertKey] = ts
+
+	p.l.Infof("Target: %s, OCSP server: %s, status changed: %s -> %s", target.Name, server, ocspStatusString(prev), ocspStatusString(status))
+
+	em := metrics.NewEventMetrics(ts).
+		AddMetric("ocsp_status_changed", metrics.NewInt(1)).
+		AddLabel("ptype", "ocsp").
+		AddLabel("probe", p.name).
+		AddLabel("ocsp-server", server).
+		AddLabel("dst", target.Name).
+		AddLabel("prev_status", ocspStatusString(prev)).
+		AddLabel("new_status", ocspStatusString(status))
+	em.Kind = metrics.GAUGE
+	for _, al := range p.opts.AdditionalLabels {
+		em.AddLabel(al.KeyValueForTarget(target))
+	}
+	return em
+}
@@ -0,0 +1,45 @@
+package ocsp
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+)
+
+// checkTargetIPs downloads the target's certificate from each of its IP
+// addresses and counts the update as inconsistent if any of them serves a
+// certificate other than cert, e.g. CDN edges serving an outdated one.
+func (p *Probe) checkTargetIPs(target endpoint.Endpoint, cert *x509.Certificate) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.opts.Timeout)
+	addrs, err := p.resolver.LookupIPAddr(ctx, targetHostname(target.Name))
+	cancel()
+	if err != nil {
+		p.l.Warningf("cannot resolve target %s to check certificates of its IPs: %v", target.Name, err)
+		return
+	}
+
+	inconsistent := false
+	for _, addr := range addrs {
+		if (p.network == "tcp4" && addr.IP.To4() == nil) || (p.network == "tcp6" && addr.IP.To4() != nil) {
+			continue
+		}
+
+		ipCert, _, err := p.downloadServerCertificate(target.Name, addr.IP)
+		if err != nil {
+			p.l.Warningf("error downloading server certificate for target %s from %s: %v", target.Name, addr.IP, err)
+			continue
+		}
+		if !bytes.Equal(ipCert.Raw, cert.Raw) {
+			p.l.Warningf("Target %s serves certificate %s from %s, but %s from another IP", target.Name, ipCert.SerialNumber.Text(16), addr.IP, cert.SerialNumber.Text(16))
+			inconsistent = true
+		}
+	}
+
+	if inconsistent {
+		p.mu.Lock()
+		p.certIPInconsistencies[target.Key()]++
+		p.mu.Unlock()
+	}
+}
@@ -0,0 +1,71 @@
+package ocsp
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// requestTiming collects the times of the phases of an OCSP HTTP request.
+type requestTiming struct {
+	sync.Mutex
+
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+	gotConn, reused           bool
+
+	// Number of TCP connections established for the request.
+	newConns int64
+}
+
+// clientTrace returns the trace hooks recording the request timing.
+func (t *requestTiming) clientTrace() *httptrace.ClientTrace {
+	record := func(ts *time.Time) {
+		t.Lock()
+		defer t.Unlock()
+		*ts = time.Now()
+	}
+
+	return &httptrace.ClientTrace{
+		ConnectStart: func(_, _ string) { record(&t.connectStart) },
+		ConnectDone: func(_, _ string, err error) {
+			record(&t.connectDone)
+			if err == nil {
+				t.Lock()
+				defer t.Unlock()
+				t.newConns++
+			}
+		},
+		TLSHandshakeStart:    func() { record(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { record(&t.wroteRequest) },
+		GotFirstResponseByte: func() { record(&t.firstByte) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.Lock()
+			defer t.Unlock()
+			t.gotConn = true
+			t.reused = info.Reused
+		},
+	}
+}
+
+// apply stores the TCP connect, TLS handshake and server processing latency
+// in the call result. Phases which didn't happen, e.g. connecting when the
+// connection was reused, are left zero.
+func (t *requestTiming) apply(call *callResult) {
+	t.Lock()
+	defer t.Unlock()
+
+	call.ConnectLatency = span(t.connectStart, t.connectDone)
+	call.TLSLatency = span(t.tlsStart, t.tlsDone)
+	call.ServerLatency = span(t.wroteRequest, t.firstByte)
+}
+
+func span(start, end time.Time) time.Duration {
+	if start.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
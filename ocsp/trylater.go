@@ -0,0 +1,45 @@
+package ocsp
+
+import (
+	"time"
+)
+
+// tryLaterPending returns true if probes of the OCSP server should be
+// skipped until its tryLater backoff passes.
+func (p *Probe) tryLaterPending(server string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, ok := p.serverTryLater[server]
+	return ok && state.nextAttempt.After(time.Now())
+}
+
+// updateTryLaterBackoff resets the OCSP server's backoff after a response
+// other than tryLater, or doubles it after a tryLater response, starting at
+// the probe interval and capped at try_later_max_backoff_sec. The server's
+// Retry-After deadline, if any, is the minimum backoff.
+func (p *Probe) updateTryLaterBackoff(server string, tryLater bool, retryAfter time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !tryLater {
+		delete(p.serverTryLater, server)
+		return
+	}
+
+	state, ok := p.serverTryLater[server]
+	if !ok {
+		state = &backoffState{}
+		p.serverTryLater[server] = state
+		p.l.Warningf("OCSP server %s asked to try later, backing off", server)
+	}
+
+	state.delay = max(2*state.delay, p.opts.Interval)
+	if maxDelay := time.Duration(p.c.GetTryLaterMaxBackoffSec()) * time.Second; state.delay > maxDelay {
+		state.delay = maxDelay
+	}
+	state.nextAttempt = time.Now().Add(state.delay)
+	if retryAfter.After(state.nextAttempt) {
+		state.nextAttempt = retryAfter
+	}
+}
@@ -0,0 +1,89 @@
+package ocsp
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+	"github.com/pkg/errors"
+)
+
+// vaultIssueResponse is the response of the Vault PKI secrets engine issue
+// endpoint.
+type vaultIssueResponse struct {
+	Data struct {
+		Certificate string `json:"certificate"`
+		IssuingCA   string `json:"issuing_ca"`
+	} `json:"data"`
+}
+
+// issueVaultCertificate issues a certificate for the target's hostname from
+// the Vault PKI secrets engine, authenticating with the VAULT_TOKEN
+// environment variable, and returns it with its issuer.
+func (p *Probe) issueVaultCertificate(target endpoint.Endpoint) (cert, issuer *x509.Certificate, err error) {
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		return nil, nil, errors.New("VAULT_TOKEN is not set")
+	}
+
+	body, err := json.Marshal(map[string]string{"common_name": targetHostname(target.Name)})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/issue/%s", strings.TrimSuffix(p.c.GetVaultAddr(), "/"), strings.Trim(p.c.GetVaultPkiMount(), "/"), p.c.GetVaultPkiRole())
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.aiaClient.Do(req)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Vault request failed")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("Vault returned status %d", resp.StatusCode)
+	}
+
+	var issued vaultIssueResponse
+	if err := json.NewDecoder(resp.Body).Decode(&issued); err != nil {
+		return nil, nil, errors.Wrap(err, "cannot decode Vault response")
+	}
+
+	if cert, err = parseCertificate([]byte(issued.Data.Certificate)); err != nil {
+		return nil, nil, errors.Wrap(err, "cannot parse issued certificate")
+	}
+	if issuer, err = parseCertificate([]byte(issued.Data.IssuingCA)); err != nil {
+		return nil, nil, errors.Wrap(err, "cannot parse issuing CA certificate")
+	}
+	return cert, issuer, nil
+}
+
+// vaultCertValid returns true if the target's certificate issued from Vault
+// doesn't need to be renewed yet, i.e. less than two thirds of its validity
+// period have passed. Without a certificate, e.g. after failed issuance, a
+// new one is needed.
+func (p *Probe) vaultCertValid(target endpoint.Endpoint) bool {
+	cert, issuer := p.getCert(target.Key())
+	if cert == nil || issuer == nil {
+		return false
+	}
+
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	return time.Until(cert.NotAfter) > lifetime/3
+}
@@ -0,0 +1,105 @@
+package ocsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudprober/cloudprober/targets/endpoint"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// revocationEvent is the JSON payload of the revocation webhook.
+type revocationEvent struct {
+	TargetName       string    `json:"target_name"`
+	OCSPServer       string    `json:"ocsp_server"`
+	CertSerial       string    `json:"cert_serial"`
+	RevokedAt        time.Time `json:"revoked_at"`
+	RevocationReason int       `json:"revocation_reason"`
+	DetectedAt       time.Time `json:"detected_at"`
+}
+
+// sendRevocationWebhook notifies revocation_webhook_url about the revoked
+// certificate of the target. Notifications for the same target, server and
+// serial are sent at most once per revocation_alert_cooldown_sec. Failed
+// notifications don't start the cooldown, so the next probe retries them.
+func (p *Probe) sendRevocationWebhook(ctx context.Context, target endpoint.Endpoint, server string, resp *ocsp.Response) {
+	serial := resp.SerialNumber.Text(16)
+	key := target.Key() + "|" + server + "|" + serial
+	now := time.Now()
+
+	p.mu.Lock()
+	cooldown := time.Duration(p.c.GetRevocationAlertCooldownSec()) * time.Second
+	last, notified := p.notifiedRevocations[key]
+	if notified && now.Sub(last) < cooldown {
+		p.mu.Unlock()
+		return
+	}
+	// Claim the notification right away, so that concurrent probes of the
+	// same certificate don't send it twice.
+	p.notifiedRevocations[key] = now
+	p.mu.Unlock()
+
+	// Release the claim if the notification isn't sent.
+	sent := false
+	defer func() {
+		if sent {
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if !p.notifiedRevocations[key].Equal(now) {
+			return
+		}
+		if notified {
+			p.notifiedRevocations[key] = last
+		} else {
+			delete(p.notifiedRevocations, key)
+		}
+	}()
+
+	body, err := json.Marshal(&revocationEvent{
+		TargetName:       target.Name,
+		OCSPServer:       server,
+		CertSerial:       serial,
+		RevokedAt:        resp.RevokedAt,
+		RevocationReason: resp.RevocationReason,
+		DetectedAt:       now,
+	})
+	if err != nil {
+		p.l.Errorf("error encoding revocation webhook for target %s: %v", target.Name, err)
+		return
+	}
+
+	if err := p.postWebhook(ctx, body); err != nil {
+		p.l.Errorf("error sending revocation webhook for target %s: %v", target.Name, err)
+		return
+	}
+	sent = true
+}
+
+func (p *Probe) postWebhook(ctx context.Context, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(p.c.GetWebhookTimeoutSec())*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.c.GetRevocationWebhookUrl(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned status %d", res.StatusCode)
+	}
+	return nil
+}
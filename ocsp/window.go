@@ -0,0 +1,38 @@
+package ocsp
+
+// rollingWindow is a ring buffer of the last probe results of an OCSP
+// server.
+type rollingWindow struct {
+	buf   []bool
+	pos   int
+	total int
+}
+
+func newRollingWindow(size int) *rollingWindow {
+	return &rollingWindow{buf: make([]bool, max(size, 1))}
+}
+
+// add records a probe result, replacing the oldest one if the buffer is
+// full.
+func (r *rollingWindow) add(success bool) {
+	r.buf[r.pos] = success
+	r.pos = (r.pos + 1) % len(r.buf)
+	r.total++
+}
+
+// rate returns the fraction of successful results in the buffer, or 0 if
+// there are none.
+func (r *rollingWindow) rate() float64 {
+	n := min(r.total, len(r.buf))
+	if n == 0 {
+		return 0
+	}
+
+	var successes int
+	for _, success := range r.buf[:n] {
+		if success {
+			successes++
+		}
+	}
+	return float64(successes) / float64(n)
+}